@@ -1,7 +1,12 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"log"
+	"slices"
+	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
 	"github.com/spf13/cobra"
@@ -9,7 +14,14 @@ import (
 
 func init() {
 	rootCmd.AddCommand(convertCmd)
-	convertCmd.PersistentFlags().StringP("config", "c", "config.json", "URI of the JSON format config file, support both local file path and remote HTTP(S) URL")
+	convertCmd.PersistentFlags().StringP("config", "c", "config.json", "URI of the JSON, YAML or TOML format config file, support both local file path and remote HTTP(S) URL")
+	convertCmd.PersistentFlags().IntP("concurrency", "j", 0, "The maximum number of inputs, outputs and files to be processed concurrently (default is the \"concurrency\" option in config file, or the number of CPUs)")
+	convertCmd.PersistentFlags().StringArray("set", nil, "Parameter to expand ${key} in config file with, in the form of \"key=value\", which takes precedence over environment variables (can be specified multiple times)")
+	convertCmd.PersistentFlags().Bool("dry-run", false, "Print the planned inputs and outputs with their resolved args, without fetching or writing anything")
+	convertCmd.PersistentFlags().Bool("validate", false, "Only check the config file, and report invalid types and args of each input and output, including unknown ones as with \"strict\" flag")
+	convertCmd.PersistentFlags().Bool("strict", false, "Reject unknown fields and args in config file, instead of ignoring them")
+
+	convertCmd.MarkFlagsMutuallyExclusive("dry-run", "validate")
 }
 
 var convertCmd = &cobra.Command{
@@ -20,6 +32,20 @@ var convertCmd = &cobra.Command{
 		configFile, _ := cmd.Flags().GetString("config")
 		log.Println("Use config:", configFile)
 
+		sets, _ := cmd.Flags().GetStringArray("set")
+		params, err := parseConfigParams(sets)
+		if err != nil {
+			log.Fatal(err)
+		}
+		lib.SetConfigParams(params)
+
+		// Validating config file always rejects unknown fields and args
+		strict, _ := cmd.Flags().GetBool("strict")
+		validate, _ := cmd.Flags().GetBool("validate")
+		if strict || validate {
+			lib.SetStrict(true)
+		}
+
 		instance, err := lib.NewInstance()
 		if err != nil {
 			log.Fatal(err)
@@ -29,8 +55,77 @@ var convertCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
+		if validate {
+			plan := instance.Plan()
+			log.Printf("✅ config is valid, with %d inputs and %d outputs\n", len(plan.Input), len(plan.Output))
+			return
+		}
+
+		if dryRun, _ := cmd.Flags().GetBool("dry-run"); dryRun {
+			printPlan(instance.Plan())
+			return
+		}
+
+		if concurrency, _ := cmd.Flags().GetInt("concurrency"); concurrency > 0 {
+			lib.SetConcurrency(concurrency)
+		}
+
 		if err := instance.Run(); err != nil {
 			log.Fatal(err)
 		}
 	},
 }
+
+// parseConfigParams parses parameters in the form of "key=value" of "set" flag.
+// The value may contain "=", and the later one of the same key takes precedence.
+func parseConfigParams(sets []string) (map[string]string, error) {
+	params := make(map[string]string, len(sets))
+	for _, set := range sets {
+		key, value, found := strings.Cut(set, "=")
+		if key = strings.TrimSpace(key); !found || key == "" {
+			return nil, fmt.Errorf("invalid argument set: %s", set)
+		}
+		params[key] = value
+	}
+	return params, nil
+}
+
+func printPlan(plan *lib.Plan) {
+	for _, stage := range []struct {
+		title string
+		steps []*lib.PlanStep
+	}{
+		{"Input", plan.Input},
+		{"Output", plan.Output},
+	} {
+		fmt.Printf("%s:\n", stage.title)
+		for _, step := range stage.steps {
+			fmt.Printf("  #%d %s (%s)\n", step.Index, step.Type, step.Action)
+
+			names := make([]string, 0, len(step.Args))
+			for name := range step.Args {
+				names = append(names, name)
+			}
+			slices.Sort(names)
+
+			for _, name := range names {
+				var value bytes.Buffer
+				encoder := json.NewEncoder(&value)
+				encoder.SetEscapeHTML(false)
+				if err := encoder.Encode(step.Args[name]); err != nil {
+					value.Reset()
+					fmt.Fprint(&value, step.Args[name])
+				}
+				fmt.Printf("      %s: %s\n", name, bytes.TrimSpace(value.Bytes()))
+			}
+
+			if len(step.Files) > 0 {
+				fmt.Println("      files:")
+				for _, file := range step.Files {
+					fmt.Printf("        %s\n", file)
+				}
+			}
+		}
+		fmt.Println()
+	}
+}
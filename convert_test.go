@@ -0,0 +1,40 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseConfigParams(t *testing.T) {
+	tests := []struct {
+		sets    []string
+		want    map[string]string
+		wantErr bool
+	}{
+		{sets: nil, want: map[string]string{}},
+		{sets: []string{"OUTPUT_DIR=./release"}, want: map[string]string{"OUTPUT_DIR": "./release"}},
+		{sets: []string{" KEY =value"}, want: map[string]string{"KEY": "value"}},
+		{sets: []string{"KEY="}, want: map[string]string{"KEY": ""}},
+		{sets: []string{"URL=https://example.com/?a=1&b=2"}, want: map[string]string{"URL": "https://example.com/?a=1&b=2"}},
+		{sets: []string{"KEY=1", "KEY=2"}, want: map[string]string{"KEY": "2"}},
+		{sets: []string{"KEY"}, wantErr: true},
+		{sets: []string{"=value"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := parseConfigParams(tt.sets)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseConfigParams(%q) = %v, want error", tt.sets, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseConfigParams(%q) error = %v", tt.sets, err)
+			continue
+		}
+		if !maps.Equal(got, tt.want) {
+			t.Errorf("parseConfigParams(%q) = %v, want %v", tt.sets, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"github.com/spf13/cobra"
+	"go4.org/netipx"
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().String("oldformat", "", "The input format of the old data. Run \"geoip list\" to see all available input formats. (Cannot be used with \"oldconfig\" flag)")
+	diffCmd.Flags().String("olduri", "", "URI of the old input file, support both local file path and remote HTTP(S) URL. (Cannot be used with \"olddir\" flag)")
+	diffCmd.Flags().String("olddir", "", "Path to the old input directory. The filename without extension will be as the name of the list. (Cannot be used with \"olduri\" flag)")
+	diffCmd.Flags().String("oldargs", "", "Args of the old input format in JSON format, the same as \"args\" in config file, which override \"olduri\" and \"olddir\" flags")
+	diffCmd.Flags().String("oldconfig", "", "URI of the config file, whose inputs are used as the old data. (Cannot be used with \"oldformat\" flag)")
+	diffCmd.Flags().String("newformat", "", "The input format of the new data (default is the same as \"oldformat\" flag)")
+	diffCmd.Flags().String("newuri", "", "URI of the new input file, support both local file path and remote HTTP(S) URL. (Cannot be used with \"newdir\" flag)")
+	diffCmd.Flags().String("newdir", "", "Path to the new input directory. The filename without extension will be as the name of the list. (Cannot be used with \"newuri\" flag)")
+	diffCmd.Flags().String("newargs", "", "Args of the new input format in JSON format, the same as \"args\" in config file, which override \"newuri\" and \"newdir\" flags")
+	diffCmd.Flags().String("newconfig", "", "URI of the config file, whose inputs are used as the new data. (Cannot be used with \"newformat\" flag)")
+	diffCmd.Flags().StringSliceP("searchlist", "l", []string{}, "The lists to compare, separated by comma (default is all lists)")
+	diffCmd.Flags().BoolP("summary", "s", false, "Only print the number of added and removed prefixes and addresses, without prefixes")
+	diffCmd.Flags().Bool("json", false, "Print the result in JSON format")
+
+	diffCmd.MarkFlagsOneRequired("oldformat", "oldconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("oldformat", "oldconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("olduri", "olddir")
+	diffCmd.MarkFlagsMutuallyExclusive("olduri", "oldconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("olddir", "oldconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("oldargs", "oldconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("newformat", "newconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("newuri", "newdir")
+	diffCmd.MarkFlagsMutuallyExclusive("newuri", "newconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("newdir", "newconfig")
+	diffCmd.MarkFlagsMutuallyExclusive("newargs", "newconfig")
+	diffCmd.MarkFlagDirname("olddir")
+	diffCmd.MarkFlagDirname("newdir")
+}
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare two geoip data list by list, and print added and removed prefixes",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		oldSource := &inputSource{name: defaultListName}
+		oldSource.format, _ = cmd.Flags().GetString("oldformat")
+		oldSource.uri, _ = cmd.Flags().GetString("olduri")
+		oldSource.dir, _ = cmd.Flags().GetString("olddir")
+		oldSource.args, _ = cmd.Flags().GetString("oldargs")
+		oldSource.config, _ = cmd.Flags().GetString("oldconfig")
+
+		newSource := &inputSource{name: defaultListName}
+		newSource.format, _ = cmd.Flags().GetString("newformat")
+		newSource.uri, _ = cmd.Flags().GetString("newuri")
+		newSource.dir, _ = cmd.Flags().GetString("newdir")
+		newSource.args, _ = cmd.Flags().GetString("newargs")
+		newSource.config, _ = cmd.Flags().GetString("newconfig")
+		if newSource.format == "" && newSource.config == "" {
+			newSource.format = oldSource.format
+		}
+		if newSource.format == "" && newSource.config == "" {
+			log.Fatal(`one of "newformat" and "newconfig" flags is required`)
+		}
+
+		searchList, _ := cmd.Flags().GetStringSlice("searchlist")
+		summary, _ := cmd.Flags().GetBool("summary")
+		printJSON, _ := cmd.Flags().GetBool("json")
+
+		oldContainer, err := loadContainer(oldSource)
+		if err != nil {
+			log.Fatal(err)
+		}
+		newContainer, err := loadContainer(newSource)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		result, err := diffContainers(oldContainer, newContainer, searchList, !summary)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		result.print()
+	},
+}
+
+type diffResult struct {
+	Lists     []*listDiff `json:"lists"`
+	Unchanged []string    `json:"unchanged"`
+}
+
+type listDiff struct {
+	Name    string      `json:"name"`
+	Status  string      `json:"status"` // added, removed or changed
+	Added   *prefixDiff `json:"added"`
+	Removed *prefixDiff `json:"removed"`
+}
+
+type prefixDiff struct {
+	PrefixCount      int            `json:"prefixCount"`
+	IPv4AddressCount *big.Int       `json:"ipv4AddressCount"`
+	IPv6AddressCount *big.Int       `json:"ipv6AddressCount"`
+	Prefixes         []netip.Prefix `json:"prefixes,omitempty"`
+}
+
+func newPrefixDiff(ipset *netipx.IPSet, withPrefixes bool) *prefixDiff {
+	prefixes := ipset.Prefixes()
+	ipv4Prefixes := make([]netip.Prefix, 0, len(prefixes))
+	ipv6Prefixes := make([]netip.Prefix, 0, len(prefixes))
+	for _, prefix := range prefixes {
+		if prefix.Addr().Is4() {
+			ipv4Prefixes = append(ipv4Prefixes, prefix)
+		} else {
+			ipv6Prefixes = append(ipv6Prefixes, prefix)
+		}
+	}
+
+	diff := &prefixDiff{
+		PrefixCount:      len(prefixes),
+		IPv4AddressCount: lib.CountAddresses(ipv4Prefixes),
+		IPv6AddressCount: lib.CountAddresses(ipv6Prefixes),
+	}
+	if withPrefixes {
+		diff.Prefixes = prefixes
+	}
+
+	return diff
+}
+
+func diffContainers(oldContainer, newContainer lib.Container, searchList []string, withPrefixes bool) (*diffResult, error) {
+	wantList := make(map[string]bool)
+	for _, name := range searchList {
+		if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+			wantList[name] = true
+		}
+	}
+
+	names := make([]string, 0, oldContainer.Len()+newContainer.Len())
+	seen := make(map[string]bool)
+	for _, container := range []lib.Container{oldContainer, newContainer} {
+		for entry := range container.Loop() {
+			name := entry.GetName()
+			if seen[name] || (len(wantList) > 0 && !wantList[name]) {
+				continue
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	result := &diffResult{
+		Lists:     make([]*listDiff, 0, len(names)),
+		Unchanged: make([]string, 0, len(names)),
+	}
+
+	for _, name := range names {
+		oldSet, oldFound, err := getIPSetForDiff(oldContainer, name)
+		if err != nil {
+			return nil, err
+		}
+		newSet, newFound, err := getIPSetForDiff(newContainer, name)
+		if err != nil {
+			return nil, err
+		}
+
+		var addedBuilder, removedBuilder netipx.IPSetBuilder
+		addedBuilder.AddSet(newSet)
+		addedBuilder.RemoveSet(oldSet)
+		removedBuilder.AddSet(oldSet)
+		removedBuilder.RemoveSet(newSet)
+
+		added, err := addedBuilder.IPSet()
+		if err != nil {
+			return nil, err
+		}
+		removed, err := removedBuilder.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		status := "changed"
+		switch {
+		case !oldFound:
+			status = "added"
+		case !newFound:
+			status = "removed"
+		case len(added.Prefixes()) == 0 && len(removed.Prefixes()) == 0:
+			result.Unchanged = append(result.Unchanged, name)
+			continue
+		}
+
+		result.Lists = append(result.Lists, &listDiff{
+			Name:    name,
+			Status:  status,
+			Added:   newPrefixDiff(added, withPrefixes),
+			Removed: newPrefixDiff(removed, withPrefixes),
+		})
+	}
+
+	return result, nil
+}
+
+func getIPSetForDiff(container lib.Container, name string) (*netipx.IPSet, bool, error) {
+	entry, found := container.GetEntry(name)
+	if !found {
+		return &netipx.IPSet{}, false, nil
+	}
+
+	ipset, err := entry.GetIPSet()
+	if err != nil {
+		return nil, false, err
+	}
+
+	return ipset, true, nil
+}
+
+func (r *diffResult) print() {
+	var addedLists, removedLists, changedLists int
+	for _, list := range r.Lists {
+		switch list.Status {
+		case "added":
+			addedLists++
+		case "removed":
+			removedLists++
+		default:
+			changedLists++
+		}
+
+		fmt.Printf("%s (%s)\n", list.Name, list.Status)
+		fmt.Printf("  added:   %s\n", list.Added)
+		fmt.Printf("  removed: %s\n", list.Removed)
+		for _, prefix := range list.Added.Prefixes {
+			fmt.Printf("  + %s\n", prefix)
+		}
+		for _, prefix := range list.Removed.Prefixes {
+			fmt.Printf("  - %s\n", prefix)
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("%d lists changed, %d added, %d removed, %d unchanged\n", changedLists, addedLists, removedLists, len(r.Unchanged))
+}
+
+func (p *prefixDiff) String() string {
+	return fmt.Sprintf("%d prefixes, %s IPv4 addresses, %s IPv6 addresses", p.PrefixCount, p.IPv4AddressCount, p.IPv6AddressCount)
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+func TestDiffContainers(t *testing.T) {
+	oldContainer, err := lib.NewContainerFromMap(map[string][]string{
+		"cn":      {"1.0.0.0/16", "2001:250::/32"},
+		"hk":      {"2.0.0.0/16"},
+		"jp":      {"3.0.0.0/16"},
+		"private": {"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	newContainer, err := lib.NewContainerFromMap(map[string][]string{
+		"cn":      {"1.0.0.0/17", "1.1.0.0/24", "2001:250::/32", "2001:251::/32"},
+		"jp":      {"3.0.0.0/16"},
+		"kr":      {"4.0.0.0/24"},
+		"private": {"10.0.0.0/8"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		searchList   []string
+		withPrefixes bool
+		lists        []string
+		unchanged    []string
+	}{
+		{
+			lists: []string{
+				"CN changed +[] (2 prefixes, 256 IPv4 addresses, 79228162514264337593543950336 IPv6 addresses) -[] (1 prefixes, 32768 IPv4 addresses, 0 IPv6 addresses)",
+				"HK removed +[] (0 prefixes, 0 IPv4 addresses, 0 IPv6 addresses) -[] (1 prefixes, 65536 IPv4 addresses, 0 IPv6 addresses)",
+				"KR added +[] (1 prefixes, 256 IPv4 addresses, 0 IPv6 addresses) -[] (0 prefixes, 0 IPv4 addresses, 0 IPv6 addresses)",
+			},
+			unchanged: []string{"JP", "PRIVATE"},
+		},
+		{
+			searchList:   []string{" cn ", "jp", ""},
+			withPrefixes: true,
+			lists: []string{
+				"CN changed +[1.1.0.0/24 2001:251::/32] (2 prefixes, 256 IPv4 addresses, 79228162514264337593543950336 IPv6 addresses) -[1.0.128.0/17] (1 prefixes, 32768 IPv4 addresses, 0 IPv6 addresses)",
+			},
+			unchanged: []string{"JP"},
+		},
+		{
+			searchList:   []string{"hk", "kr"},
+			withPrefixes: true,
+			lists: []string{
+				"HK removed +[] (0 prefixes, 0 IPv4 addresses, 0 IPv6 addresses) -[2.0.0.0/16] (1 prefixes, 65536 IPv4 addresses, 0 IPv6 addresses)",
+				"KR added +[4.0.0.0/24] (1 prefixes, 256 IPv4 addresses, 0 IPv6 addresses) -[] (0 prefixes, 0 IPv4 addresses, 0 IPv6 addresses)",
+			},
+			unchanged: []string{},
+		},
+		{
+			searchList: []string{"us"},
+			lists:      []string{},
+			unchanged:  []string{},
+		},
+	}
+
+	format := func(list *listDiff) string {
+		return fmt.Sprintf("%s %s +%v (%s) -%v (%s)", list.Name, list.Status, list.Added.Prefixes, list.Added, list.Removed.Prefixes, list.Removed)
+	}
+
+	for _, tt := range tests {
+		result, err := diffContainers(oldContainer, newContainer, tt.searchList, tt.withPrefixes)
+		if err != nil {
+			t.Fatalf("diffContainers(%q) error = %v", tt.searchList, err)
+		}
+
+		lists := make([]string, 0, len(result.Lists))
+		for _, list := range result.Lists {
+			lists = append(lists, format(list))
+		}
+		if !slices.Equal(lists, tt.lists) {
+			t.Errorf("diffContainers(%q) lists = %q, want %q", tt.searchList, lists, tt.lists)
+		}
+		if !slices.Equal(result.Unchanged, tt.unchanged) {
+			t.Errorf("diffContainers(%q) unchanged = %v, want %v", tt.searchList, result.Unchanged, tt.unchanged)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"github.com/spf13/cobra"
+)
+
+// inputSource is the input of commands reading data, like lookup and stats,
+// which is either an input format with its args, or the inputs of a config file.
+type inputSource struct {
+	format string
+	name   string // name of the list for input formats that need a name
+	uri    string
+	dir    string
+	args   string // args of the input format in JSON format
+	config string
+}
+
+// addInputFlags adds the flags to specify the input source.
+func addInputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("format", "f", "", "The input format. Run \"geoip list\" to see all available input formats. (Cannot be used with \"config\" flag)")
+	cmd.Flags().StringP("uri", "u", "", "URI of the input file, support both local file path and remote HTTP(S) URL. (Cannot be used with \"dir\" flag)")
+	cmd.Flags().StringP("dir", "d", "", "Path to the input directory. The filename without extension will be as the name of the list. (Cannot be used with \"uri\" flag)")
+	cmd.Flags().String("args", "", "Args of the input format in JSON format, the same as \"args\" in config file, which override \"uri\" and \"dir\" flags")
+	cmd.Flags().StringP("config", "c", "", "URI of the config file, whose inputs are used as the input, support both local file path and remote HTTP(S) URL. (Cannot be used with \"format\" flag)")
+
+	cmd.MarkFlagsOneRequired("format", "config")
+	cmd.MarkFlagsMutuallyExclusive("format", "config")
+	cmd.MarkFlagsMutuallyExclusive("uri", "dir")
+	cmd.MarkFlagsMutuallyExclusive("uri", "config")
+	cmd.MarkFlagsMutuallyExclusive("dir", "config")
+	cmd.MarkFlagsMutuallyExclusive("args", "config")
+	cmd.MarkFlagDirname("dir")
+}
+
+// getInputSource returns the input source specified by the flags added by addInputFlags.
+func getInputSource(cmd *cobra.Command, name string) *inputSource {
+	source := &inputSource{name: name}
+	source.format, _ = cmd.Flags().GetString("format")
+	source.uri, _ = cmd.Flags().GetString("uri")
+	source.dir, _ = cmd.Flags().GetString("dir")
+	source.args, _ = cmd.Flags().GetString("args")
+	source.config, _ = cmd.Flags().GetString("config")
+	return source
+}
+
+// newInputConverter creates the converter of the input format from the registry.
+func (s *inputSource) newInputConverter() (lib.InputConverter, error) {
+	format := strings.TrimSpace(s.format)
+
+	// Flags are converted to args, which must be supported by the input format
+	schema, hasSchema := lib.GetInputConverterSchema(format)
+	hasArg := func(name string) bool {
+		return !hasSchema || schema.Arg(name) != nil
+	}
+
+	args := make(map[string]any)
+	switch {
+	case s.uri != "":
+		if !hasArg("uri") {
+			return nil, fmt.Errorf("input format %s does not support flag uri, use flag args instead", format)
+		}
+		args["uri"] = s.uri
+	case s.dir != "":
+		if !hasArg("inputDir") {
+			return nil, fmt.Errorf("input format %s does not support flag dir, use flag args instead", format)
+		}
+		args["inputDir"] = s.dir
+	}
+	if s.dir == "" && hasArg("name") {
+		args["name"] = s.name
+	}
+
+	if s.args = strings.TrimSpace(s.args); s.args != "" {
+		if err := json.Unmarshal([]byte(s.args), &args); err != nil {
+			return nil, fmt.Errorf("invalid args: %w", err)
+		}
+	}
+
+	data, err := json.Marshal(args)
+	if err != nil {
+		return nil, err
+	}
+
+	return lib.CreateInputConverter(format, lib.ActionAdd, data)
+}
+
+// newInstance returns a new instance with the inputs of the source and no output.
+func (s *inputSource) newInstance() (lib.Instance, error) {
+	instance, err := lib.NewInstance()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.config != "" {
+		if err := instance.InitConfig(s.config); err != nil {
+			return nil, err
+		}
+		instance.ResetOutput()
+		return instance, nil
+	}
+
+	input, err := s.newInputConverter()
+	if err != nil {
+		return nil, err
+	}
+	instance.AddInput(input)
+
+	return instance, nil
+}
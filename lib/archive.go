@@ -0,0 +1,249 @@
+package lib
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+var (
+	gzipMagic = []byte{0x1f, 0x8b}
+	zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	zipMagic  = []byte{'P', 'K', 0x03, 0x04}
+	tarMagic  = []byte("ustar")
+)
+
+// tarMagicOffset is the offset of the magic in a tar header
+const tarMagicOffset = 257
+
+// OpenURI opens uri, which can be a local file path or a remote http(s) URL.
+// Files are verified against checksum, if any.
+// Files compressed by gzip or zstd, and tar or zip archives, are detected
+// by their content and decompressed transparently. A file inside an archive
+// can be selected by appending `#path/in/archive` to uri, which matches
+// the full path or the last elements of the path. For remote URLs that are
+// not archives, the part after `#` is a URL fragment and is ignored.
+func OpenURI(uri string, checksum *Checksum, httpConfig *HTTPConfig) (io.ReadCloser, error) {
+	uri, member := splitArchiveMember(uri)
+
+	var file io.ReadCloser
+	var err error
+	isRemote := false
+	switch {
+	case strings.HasPrefix(strings.ToLower(uri), "http://"), strings.HasPrefix(strings.ToLower(uri), "https://"):
+		isRemote = true
+		file, err = GetVerifiedRemoteURLReader(uri, checksum, httpConfig)
+	default:
+		file, err = OpenVerifiedLocalFile(uri, checksum, httpConfig)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := decompress(file, member, isRemote)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read %s: %v", uri, err)
+	}
+
+	return reader, nil
+}
+
+// ReadURI is like OpenURI, but returns all the content.
+func ReadURI(uri string, checksum *Checksum, httpConfig *HTTPConfig) ([]byte, error) {
+	reader, err := OpenURI(uri, checksum, httpConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
+
+// TrimArchiveMember returns uri without the file selected in archive, if any,
+// i.e. the URI actually fetched.
+func TrimArchiveMember(uri string) string {
+	uri, _ = splitArchiveMember(uri)
+	return uri
+}
+
+func splitArchiveMember(uri string) (string, string) {
+	index := strings.LastIndex(uri, "#")
+	if index < 0 {
+		return uri, ""
+	}
+
+	// Local files with "#" in their names
+	if _, err := os.Stat(uri); err == nil {
+		return uri, ""
+	}
+
+	return uri[:index], strings.Trim(uri[index+1:], "/")
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (r *readCloser) Close() error {
+	return r.close()
+}
+
+// decompress wraps file to decompress it and extract member from archives.
+// Content that is neither compressed nor archived is returned as is,
+// or an error is returned if member is specified, unless ignoreMember
+// is true, i.e. member may be something else than a file in archive.
+func decompress(file io.ReadCloser, member string, ignoreMember bool) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(file)
+	header, _ := buffered.Peek(tarMagicOffset + len(tarMagic))
+
+	switch {
+	case bytes.HasPrefix(header, gzipMagic):
+		gzipReader, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return extractTar(gzipReader, file, member, ignoreMember)
+
+	case bytes.HasPrefix(header, zstdMagic):
+		zstdReader, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		return extractTar(zstdReader.IOReadCloser(), file, member, ignoreMember)
+
+	case bytes.HasPrefix(header, zipMagic):
+		content, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, err
+		}
+		file.Close()
+		return extractZip(content, member)
+
+	case isTar(header):
+		return extractTar(io.NopCloser(buffered), file, member, ignoreMember)
+	}
+
+	if member != "" && !ignoreMember {
+		return nil, fmt.Errorf("cannot select %s as it is not an archive", member)
+	}
+
+	return &readCloser{Reader: buffered, close: file.Close}, nil
+}
+
+func isTar(header []byte) bool {
+	return len(header) >= tarMagicOffset+len(tarMagic) &&
+		bytes.Equal(header[tarMagicOffset:tarMagicOffset+len(tarMagic)], tarMagic)
+}
+
+// extractTar returns the decompressed stream of r if it is not a tar archive,
+// or the content of member in the tar archive otherwise.
+func extractTar(r io.ReadCloser, file io.Closer, member string, ignoreMember bool) (io.ReadCloser, error) {
+	closeAll := func() error {
+		r.Close()
+		return file.Close()
+	}
+
+	buffered := bufio.NewReader(r)
+	header, _ := buffered.Peek(tarMagicOffset + len(tarMagic))
+	if !isTar(header) {
+		if member != "" && !ignoreMember {
+			closeAll()
+			return nil, fmt.Errorf("cannot select %s as it is not an archive", member)
+		}
+		return &readCloser{Reader: buffered, close: closeAll}, nil
+	}
+	defer closeAll()
+
+	names := make([]string, 0)
+	var content []byte
+	tarReader := tar.NewReader(buffered)
+	for {
+		hdr, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		names = append(names, hdr.Name)
+		if matchArchiveMember(hdr.Name, member) {
+			if content, err = io.ReadAll(tarReader); err != nil {
+				return nil, err
+			}
+			if member != "" {
+				return io.NopCloser(bytes.NewReader(content)), nil
+			}
+		}
+	}
+
+	return selectArchiveMember(names, member, content)
+}
+
+// extractZip returns the content of member in the zip archive.
+func extractZip(content []byte, member string) (io.ReadCloser, error) {
+	zipReader, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(zipReader.File))
+	var matched *zip.File
+	for _, f := range zipReader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+
+		names = append(names, f.Name)
+		if matchArchiveMember(f.Name, member) {
+			matched = f
+			if member != "" {
+				break
+			}
+		}
+	}
+
+	if matched == nil || (member == "" && len(names) > 1) {
+		return selectArchiveMember(names, member, nil)
+	}
+
+	return matched.Open()
+}
+
+func matchArchiveMember(name, member string) bool {
+	if member == "" {
+		return true
+	}
+
+	name = path.Clean(strings.TrimPrefix(name, "./"))
+	return name == member || strings.HasSuffix(name, "/"+member)
+}
+
+// selectArchiveMember returns content if it is the only file in the archive
+// when member is not specified, or an error listing files in the archive.
+func selectArchiveMember(names []string, member string, content []byte) (io.ReadCloser, error) {
+	switch {
+	case len(names) == 0:
+		return nil, fmt.Errorf("no file found in archive")
+	case member != "":
+		return nil, fmt.Errorf("%s not found in archive, files in archive: %s", member, strings.Join(names, ", "))
+	case len(names) > 1:
+		return nil, fmt.Errorf("multiple files found in archive, select one by appending `#filename` to the path: %s", strings.Join(names, ", "))
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
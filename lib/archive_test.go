@@ -0,0 +1,195 @@
+package lib
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+type archiveFile struct {
+	name    string
+	content string
+}
+
+func makeTar(t *testing.T, files ...archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := tar.NewWriter(&buf)
+	for _, f := range files {
+		if err := w.WriteHeader(&tar.Header{Name: f.name, Mode: 0644, Size: int64(len(f.content)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, files ...archiveFile) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, f := range files {
+		fw, err := w.Create(f.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(f.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func makeGzip(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func makeZstd(t *testing.T, content []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w, err := zstd.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write(content); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func writeTempFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestOpenURIDetectsFormat(t *testing.T) {
+	single := archiveFile{"dir/list.txt", "1.0.0.0/24\n"}
+	multiple := []archiveFile{single, {"dir/other.txt", "2.0.0.0/24\n"}}
+
+	tests := []struct {
+		name    string
+		content []byte
+		member  string
+		want    string
+		wantErr string
+	}{
+		{name: "plain", content: []byte("1.0.0.0/24\n"), want: "1.0.0.0/24\n"},
+		{name: "gzip", content: makeGzip(t, []byte("1.0.0.0/24\n")), want: "1.0.0.0/24\n"},
+		{name: "zstd", content: makeZstd(t, []byte("1.0.0.0/24\n")), want: "1.0.0.0/24\n"},
+		{name: "tar", content: makeTar(t, single), want: single.content},
+		{name: "tar.gz", content: makeGzip(t, makeTar(t, single)), want: single.content},
+		{name: "tar.zst", content: makeZstd(t, makeTar(t, single)), want: single.content},
+		{name: "zip", content: makeZip(t, single), want: single.content},
+		{name: "tar.gz member by full path", content: makeGzip(t, makeTar(t, multiple...)), member: "dir/other.txt", want: "2.0.0.0/24\n"},
+		{name: "tar.gz member by filename", content: makeGzip(t, makeTar(t, multiple...)), member: "other.txt", want: "2.0.0.0/24\n"},
+		{name: "zip member by filename", content: makeZip(t, multiple...), member: "list.txt", want: "1.0.0.0/24\n"},
+		{name: "zip member with slashes", content: makeZip(t, multiple...), member: "/dir/list.txt/", want: "1.0.0.0/24\n"},
+		{name: "member of partial filename", content: makeZip(t, multiple...), member: "ist.txt", wantErr: "ist.txt not found in archive"},
+		{name: "tar member not found", content: makeTar(t, multiple...), member: "missing.txt", wantErr: "missing.txt not found in archive"},
+		{name: "tar multiple files", content: makeTar(t, multiple...), wantErr: "multiple files found in archive"},
+		{name: "zip multiple files", content: makeZip(t, multiple...), wantErr: "multiple files found in archive"},
+		{name: "member of plain", content: []byte("1.0.0.0/24\n"), member: "list.txt", wantErr: "not an archive"},
+		{name: "member of gzip", content: makeGzip(t, []byte("1.0.0.0/24\n")), member: "list.txt", wantErr: "not an archive"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uri := writeTempFile(t, "file", tt.content)
+			if tt.member != "" {
+				uri += "#" + tt.member
+			}
+
+			got, err := ReadURI(uri, nil, nil)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ReadURI() error = %v, want error containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReadURI() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("ReadURI() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOpenURILocalFileWithHash(t *testing.T) {
+	uri := writeTempFile(t, "list#1.txt", []byte("1.0.0.0/24\n"))
+
+	got, err := ReadURI(uri, nil, nil)
+	if err != nil {
+		t.Fatalf("ReadURI() error = %v", err)
+	}
+	if string(got) != "1.0.0.0/24\n" {
+		t.Errorf("ReadURI() = %q, want %q", got, "1.0.0.0/24\n")
+	}
+}
+
+func TestOpenURIRemoteFragment(t *testing.T) {
+	archive := makeZip(t, archiveFile{"a.txt", "1.0.0.0/24\n"}, archiveFile{"b.txt", "2.0.0.0/24\n"})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/list.zip":
+			w.Write(archive)
+		case "/list.txt":
+			w.Write([]byte("3.0.0.0/24\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		uri  string
+		want string
+	}{
+		{uri: server.URL + "/list.zip#b.txt", want: "2.0.0.0/24\n"},
+		{uri: server.URL + "/list.txt#section", want: "3.0.0.0/24\n"},
+	}
+
+	for _, tt := range tests {
+		got, err := ReadURI(tt.uri, nil, nil)
+		if err != nil {
+			t.Fatalf("ReadURI(%s) error = %v", tt.uri, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("ReadURI(%s) = %q, want %q", tt.uri, got, tt.want)
+		}
+	}
+}
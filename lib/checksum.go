@@ -0,0 +1,204 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Checksum is the expected checksum of local or remote files, specified either
+// directly or by the URI of a checksum file in the format of `sha256sum`.
+type Checksum struct {
+	SHA256    SHA256 `json:"sha256"`
+	SHA256URI string `json:"sha256URI"`
+
+	// Whether the checksum file is for multiple files fetched from different URIs,
+	// so that checksums must be looked up by filename
+	multiFile bool
+	// The checksum file shared by copies of the checksum, to fetch it only once
+	file *checksumFile
+}
+
+type checksumFile struct {
+	once sync.Once
+	sums map[string]string
+	err  error
+}
+
+// ForFiles returns a copy of c to verify multiple files of a converter,
+// which fetches the checksum file only once. If the files are fetched from
+// different URIs, a checksum file with only one line is not used for all of them.
+func (c Checksum) ForFiles(sameURI bool) Checksum {
+	c.multiFile = !sameURI
+	c.file = new(checksumFile)
+	return c
+}
+
+// WithSHA256 returns a copy of c with sha256 as the expected checksum if it is not empty.
+func (c Checksum) WithSHA256(sha256 SHA256) *Checksum {
+	if sha256 != "" {
+		c.SHA256 = sha256
+	}
+	return &c
+}
+
+func (c *Checksum) IsEmpty() bool {
+	return c == nil || (c.SHA256 == "" && strings.TrimSpace(c.SHA256URI) == "")
+}
+
+// SHA256 is a SHA-256 checksum in lowercase hex,
+// which is validated when unmarshaling.
+type SHA256 string
+
+func (s *SHA256) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		return fmt.Errorf("invalid sha256 checksum %s", data)
+	}
+
+	if strings.TrimSpace(str) == "" {
+		*s = ""
+		return nil
+	}
+
+	sum, err := normalizeSHA256(str)
+	if err != nil {
+		return err
+	}
+	*s = SHA256(sum)
+	return nil
+}
+
+// Verify returns an error if the SHA-256 checksum of content
+// fetched from uri does not match the expected one.
+func (c *Checksum) Verify(uri string, content []byte, httpConfig *HTTPConfig) error {
+	if c.IsEmpty() {
+		return nil
+	}
+
+	expected, err := c.expected(uri, httpConfig)
+	if err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(content)
+	if actual := hex.EncodeToString(sum[:]); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected sha256 %s, got %s", uri, expected, actual)
+	}
+
+	return nil
+}
+
+func (c *Checksum) expected(uri string, httpConfig *HTTPConfig) (string, error) {
+	if c.SHA256 != "" {
+		return normalizeSHA256(string(c.SHA256))
+	}
+
+	sumURI := strings.TrimSpace(c.SHA256URI)
+	var sums map[string]string
+	var err error
+	if c.file != nil {
+		c.file.once.Do(func() {
+			c.file.sums, c.file.err = readChecksumFile(sumURI, httpConfig)
+		})
+		sums, err = c.file.sums, c.file.err
+	} else {
+		sums, err = readChecksumFile(sumURI, httpConfig)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// A checksum file with only one line is for the file fetched,
+	// whatever the filename in it is, as the filename often differs from the URL.
+	if len(sums) == 1 && !c.multiFile {
+		for _, sum := range sums {
+			return sum, nil
+		}
+	}
+
+	filename := uriBase(uri)
+	sum, found := sums[filename]
+	if !found {
+		return "", fmt.Errorf("checksum of %s not found in checksum file %s", filename, sumURI)
+	}
+
+	return sum, nil
+}
+
+func readChecksumFile(sumURI string, httpConfig *HTTPConfig) (map[string]string, error) {
+	var content []byte
+	var err error
+	switch {
+	case strings.HasPrefix(strings.ToLower(sumURI), "http://"), strings.HasPrefix(strings.ToLower(sumURI), "https://"):
+		content, err = GetRemoteURLContent(sumURI, httpConfig)
+	default:
+		content, err = os.ReadFile(sumURI)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sums, err := parseChecksumFile(content)
+	if err != nil {
+		return nil, fmt.Errorf("invalid checksum file %s: %v", sumURI, err)
+	}
+
+	return sums, nil
+}
+
+// parseChecksumFile parses content in the format of `sha256sum`,
+// i.e. lines of checksum and filename, into a map of filename to checksum.
+func parseChecksumFile(content []byte) (map[string]string, error) {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		sum, filename, _ := strings.Cut(line, " ")
+		sum, err := normalizeSHA256(sum)
+		if err != nil {
+			return nil, err
+		}
+
+		// Filename is prefixed with "*" in binary mode
+		filename = strings.TrimPrefix(strings.TrimSpace(filename), "*")
+		sums[path.Base(filepath.ToSlash(filename))] = sum
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(sums) == 0 {
+		return nil, fmt.Errorf("no checksum found")
+	}
+
+	return sums, nil
+}
+
+func normalizeSHA256(sum string) (string, error) {
+	sum = strings.ToLower(strings.TrimSpace(sum))
+	if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+		return "", fmt.Errorf("invalid sha256 checksum %q, which must be 64 hex characters", sum)
+	}
+	return sum, nil
+}
+
+func uriBase(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Scheme != "" && u.Path != "" {
+		return path.Base(u.Path)
+	}
+	return filepath.Base(uri)
+}
@@ -0,0 +1,158 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestChecksumUnmarshal(t *testing.T) {
+	sum := sha256Hex("1.0.0.0/24\n")
+
+	tests := []struct {
+		data    string
+		want    SHA256
+		wantErr bool
+	}{
+		{data: `{}`},
+		{data: `{"sha256": ""}`},
+		{data: `{"sha256": "` + sum + `"}`, want: SHA256(sum)},
+		{data: `{"sha256": " ` + strings.ToUpper(sum) + ` "}`, want: SHA256(sum)},
+		{data: `{"sha256": "00"}`, wantErr: true},
+		{data: `{"sha256": "` + sum[:63] + `g"}`, wantErr: true},
+		{data: `{"sha256": "` + sum + `00"}`, wantErr: true},
+		{data: `{"sha256": 1}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		var checksum Checksum
+		err := json.Unmarshal([]byte(tt.data), &checksum)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, want error: %v", tt.data, err, tt.wantErr)
+			continue
+		}
+		if err == nil && checksum.SHA256 != tt.want {
+			t.Errorf("Unmarshal(%s) sha256 = %s, want %s", tt.data, checksum.SHA256, tt.want)
+		}
+	}
+}
+
+func TestParseChecksumFile(t *testing.T) {
+	sumA, sumB := sha256Hex("a"), sha256Hex("b")
+
+	sums, err := parseChecksumFile([]byte("# comment\n" + sumA + "  a.txt\n" + strings.ToUpper(sumB) + " *dir/b.txt\n\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 2 || sums["a.txt"] != sumA || sums["b.txt"] != sumB {
+		t.Errorf("parseChecksumFile() = %v, want a.txt: %s, b.txt: %s", sums, sumA, sumB)
+	}
+
+	for _, content := range []string{"", "# comment\n", "00  a.txt\n"} {
+		if _, err := parseChecksumFile([]byte(content)); err == nil {
+			t.Errorf("parseChecksumFile(%q) error = nil, want error", content)
+		}
+	}
+}
+
+func TestChecksumVerify(t *testing.T) {
+	content := "1.0.0.0/24\n"
+	sum := sha256Hex(content)
+	other := sha256Hex("other")
+
+	dir := writeConfigFiles(t, map[string]string{
+		"one.sha256":   other + "  anything.txt\n",
+		"multi.sha256": other + "  other.txt\n" + sum + "  cn.txt\n",
+	})
+	oneLine := filepath.Join(dir, "one.sha256")
+	multiLine := filepath.Join(dir, "multi.sha256")
+
+	tests := []struct {
+		name     string
+		checksum *Checksum
+		uri      string
+		wantErr  string
+	}{
+		{name: "empty", checksum: &Checksum{}, uri: "cn.txt"},
+		{name: "nil", uri: "cn.txt"},
+		{name: "sha256 match", checksum: &Checksum{SHA256: SHA256(sum)}, uri: "cn.txt"},
+		{name: "sha256 mismatch", checksum: &Checksum{SHA256: SHA256(other)}, uri: "cn.txt", wantErr: "checksum mismatch"},
+		{name: "checksum file by filename", checksum: &Checksum{SHA256URI: multiLine}, uri: "https://example.com/dir/cn.txt?key=1"},
+		{name: "checksum file missing filename", checksum: &Checksum{SHA256URI: multiLine}, uri: "us.txt", wantErr: "not found in checksum file"},
+		{name: "checksum file with one line", checksum: &Checksum{SHA256URI: oneLine}, uri: "cn.txt", wantErr: "checksum mismatch"},
+		{name: "checksum file with one line for multiple files", checksum: Checksum{SHA256URI: oneLine}.ForFiles(false).WithSHA256(""), uri: "cn.txt", wantErr: "not found in checksum file"},
+		{name: "sha256 overriding checksum file", checksum: Checksum{SHA256URI: oneLine}.WithSHA256(SHA256(sum)), uri: "cn.txt"},
+		{name: "missing checksum file", checksum: &Checksum{SHA256URI: filepath.Join(dir, "nope")}, uri: "cn.txt", wantErr: "nope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.checksum.Verify(tt.uri, []byte(content), nil)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Verify() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Verify() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChecksumFileFetchedOnce(t *testing.T) {
+	sumA, sumB := sha256Hex("a"), sha256Hex("b")
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write([]byte(sumA + "  a.txt\n" + sumB + "  b.txt\n"))
+	}))
+	defer server.Close()
+
+	checksum := Checksum{SHA256URI: server.URL + "/SHA256SUMS"}.ForFiles(false)
+	if err := checksum.WithSHA256("").Verify("a.txt", []byte("a"), nil); err != nil {
+		t.Errorf("Verify(a.txt) error = %v", err)
+	}
+	if err := checksum.WithSHA256("").Verify("b.txt", []byte("b"), nil); err != nil {
+		t.Errorf("Verify(b.txt) error = %v", err)
+	}
+	if got := requests.Load(); got != 1 {
+		t.Errorf("checksum file fetched %d times, want 1", got)
+	}
+}
+
+func TestOpenURIVerifiesLocalFile(t *testing.T) {
+	content := "1.0.0.0/24\n"
+	dir := writeConfigFiles(t, map[string]string{"cn.txt": content})
+	path := filepath.Join(dir, "cn.txt")
+
+	if _, err := ReadURI(path, &Checksum{SHA256: SHA256(sha256Hex("other"))}, nil); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("ReadURI() error = %v, want checksum mismatch", err)
+	}
+
+	got, err := ReadURI(path, &Checksum{SHA256: SHA256(sha256Hex(content))}, nil)
+	if err != nil {
+		t.Fatalf("ReadURI() error = %v", err)
+	}
+	if string(got) != content {
+		t.Errorf("ReadURI() = %q, want %q", got, content)
+	}
+
+	if _, err := OpenVerifiedLocalFile(filepath.Join(dir, "nope.txt"), &Checksum{SHA256: SHA256(sha256Hex(content))}, nil); !os.IsNotExist(err) {
+		t.Errorf("OpenVerifiedLocalFile() error = %v, want not exist error", err)
+	}
+}
@@ -1,37 +1,88 @@
 package lib
 
 import (
+	"bytes"
 	"encoding/json"
-	"fmt"
 	"io"
-	"net/http"
+	"math/big"
+	"net/netip"
+	"os"
 )
 
-func GetRemoteURLContent(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+func GetRemoteURLContent(url string, httpConfig ...*HTTPConfig) ([]byte, error) {
+	body, err := fetchRemoteURL(url, httpConfig...)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	defer body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get remote content -> %s: %s", url, resp.Status)
+	return io.ReadAll(body)
+}
+
+func GetRemoteURLReader(url string, httpConfig ...*HTTPConfig) (io.ReadCloser, error) {
+	return fetchRemoteURL(url, httpConfig...)
+}
+
+// GetVerifiedRemoteURLContent is like GetRemoteURLContent,
+// but fails if the content does not match the expected checksum.
+func GetVerifiedRemoteURLContent(url string, checksum *Checksum, httpConfig *HTTPConfig) ([]byte, error) {
+	content, err := GetRemoteURLContent(url, httpConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	return io.ReadAll(resp.Body)
+	if err := checksum.Verify(url, content, httpConfig); err != nil {
+		return nil, err
+	}
+
+	return content, nil
 }
 
-func GetRemoteURLReader(url string) (io.ReadCloser, error) {
-	resp, err := http.Get(url)
+// GetVerifiedRemoteURLReader is like GetRemoteURLReader,
+// but fails if the content does not match the expected checksum.
+// The content is read into memory for verification if checksum is not empty.
+func GetVerifiedRemoteURLReader(url string, checksum *Checksum, httpConfig *HTTPConfig) (io.ReadCloser, error) {
+	if checksum.IsEmpty() {
+		return GetRemoteURLReader(url, httpConfig)
+	}
+
+	content, err := GetVerifiedRemoteURLContent(url, checksum, httpConfig)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get remote content -> %s: %s", url, resp.Status)
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// OpenVerifiedLocalFile opens the local file at path, but fails if the content
+// does not match the expected checksum. The content is read into memory
+// for verification if checksum is not empty.
+func OpenVerifiedLocalFile(path string, checksum *Checksum, httpConfig *HTTPConfig) (io.ReadCloser, error) {
+	if checksum.IsEmpty() {
+		return os.Open(path)
 	}
 
-	return resp.Body, nil
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := checksum.Verify(path, content, httpConfig); err != nil {
+		return nil, err
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+// CountAddresses returns the number of addresses in prefixes,
+// which are expected not to overlap.
+func CountAddresses(prefixes []netip.Prefix) *big.Int {
+	count := new(big.Int)
+	size := new(big.Int)
+	for _, prefix := range prefixes {
+		size.Lsh(big.NewInt(1), uint(prefix.Addr().BitLen()-prefix.Bits()))
+		count.Add(count, size)
+	}
+	return count
 }
 
 func GetIgnoreIPType(onlyIPType IPType) IgnoreIPOption {
@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"runtime"
+	"sync"
+)
+
+var (
+	concurrency = runtime.NumCPU()
+
+	// limiter is shared by all calls of ForEachConcurrently, including nested ones,
+	// so that at most concurrency goroutines do work at the same time. The goroutine
+	// calling ForEachConcurrently is one of them, so it holds concurrency-1 slots.
+	limiter = make(chan struct{}, concurrency-1)
+)
+
+// SetConcurrency sets the maximum number of converters or lists to be processed
+// at the same time. A value less than 1 resets it to the number of CPUs.
+func SetConcurrency(n int) {
+	if n < 1 {
+		n = runtime.NumCPU()
+	}
+	concurrency = n
+	limiter = make(chan struct{}, n-1)
+}
+
+func GetConcurrency() int {
+	return concurrency
+}
+
+// ForEachConcurrently calls fn for every item with at most GetConcurrency()
+// goroutines at the same time, counted together with nested calls, like
+// lists processed concurrently by outputs processed concurrently. Items are
+// processed by the calling goroutine when no other goroutine is available.
+// It stops starting new calls once an error occurs, and returns the first error.
+func ForEachConcurrently[T any](items []T, fn func(T) error) error {
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	failed := make(chan struct{})
+	limiter := limiter
+
+	setErr := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			close(failed)
+		})
+	}
+
+loop:
+	for _, item := range items {
+		select {
+		case <-failed:
+			break loop
+		default:
+		}
+
+		select {
+		case limiter <- struct{}{}:
+			wg.Add(1)
+			go func() {
+				defer func() {
+					<-limiter
+					wg.Done()
+				}()
+
+				if err := fn(item); err != nil {
+					setErr(err)
+				}
+			}()
+
+		default:
+			if err := fn(item); err != nil {
+				setErr(err)
+			}
+		}
+	}
+
+	wg.Wait()
+
+	return firstErr
+}
@@ -0,0 +1,271 @@
+package lib
+
+import (
+	"errors"
+	"path/filepath"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestForEachConcurrentlyNested(t *testing.T) {
+	defer SetConcurrency(0)
+	SetConcurrency(3)
+
+	var running, maxRunning atomic.Int32
+	work := func() {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		running.Add(-1)
+	}
+
+	var count atomic.Int32
+	outer := make([]int, 5)
+	inner := make([]int, 10)
+	err := ForEachConcurrently(outer, func(int) error {
+		return ForEachConcurrently(inner, func(int) error {
+			work()
+			count.Add(1)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatalf("ForEachConcurrently() error = %v", err)
+	}
+
+	if got := count.Load(); got != int32(len(outer)*len(inner)) {
+		t.Errorf("processed %d items, want %d", got, len(outer)*len(inner))
+	}
+	if got := maxRunning.Load(); got > 3 {
+		t.Errorf("%d items processed at the same time, want at most 3", got)
+	}
+}
+
+func TestForEachConcurrentlyError(t *testing.T) {
+	wantErr := errors.New("failed")
+	err := ForEachConcurrently([]int{1, 2, 3}, func(i int) error {
+		if i == 2 {
+			return wantErr
+		}
+		return nil
+	})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("ForEachConcurrently() error = %v, want %v", err, wantErr)
+	}
+}
+
+type fakeOutput struct {
+	name   string
+	stdout bool
+	mu     *sync.Mutex
+	order  *[]string
+}
+
+func (f *fakeOutput) GetType() string        { return f.name }
+func (f *fakeOutput) GetAction() Action      { return ActionOutput }
+func (f *fakeOutput) GetDescription() string { return "" }
+func (f *fakeOutput) OutputsToStdout() bool  { return f.stdout }
+func (f *fakeOutput) Output(Container) error {
+	time.Sleep(time.Millisecond)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	*f.order = append(*f.order, f.name)
+	return nil
+}
+
+func TestRunOutputStdoutInOrder(t *testing.T) {
+	defer SetConcurrency(0)
+	SetConcurrency(8)
+
+	var mu sync.Mutex
+	order := make([]string, 0)
+	instance, _ := NewInstance()
+	for _, name := range []string{"stdout1", "file1", "stdout2", "file2", "stdout3"} {
+		instance.AddOutput(&fakeOutput{
+			name:   name,
+			stdout: name[:4] != "file",
+			mu:     &mu,
+			order:  &order,
+		})
+	}
+
+	if err := instance.RunOutput(NewContainer()); err != nil {
+		t.Fatalf("RunOutput() error = %v", err)
+	}
+
+	stdoutOrder := slices.DeleteFunc(slices.Clone(order), func(name string) bool {
+		return name[:4] == "file"
+	})
+	if want := []string{"stdout1", "stdout2", "stdout3"}; !slices.Equal(stdoutOrder, want) {
+		t.Errorf("stdout outputs ran in order %v, want %v", stdoutOrder, want)
+	}
+	if len(order) != 5 {
+		t.Errorf("ran %d outputs, want 5", len(order))
+	}
+}
+
+type fakePrefetchInput struct {
+	name              string
+	pending, maxValue *atomic.Int32
+	mu                *sync.Mutex
+	order             *[]string
+}
+
+func (f *fakePrefetchInput) GetType() string        { return f.name }
+func (f *fakePrefetchInput) GetAction() Action      { return ActionAdd }
+func (f *fakePrefetchInput) GetDescription() string { return "" }
+func (f *fakePrefetchInput) Prefetch() error {
+	n := f.pending.Add(1)
+	for {
+		m := f.maxValue.Load()
+		if n <= m || f.maxValue.CompareAndSwap(m, n) {
+			break
+		}
+	}
+	return nil
+}
+func (f *fakePrefetchInput) Input(container Container) (Container, error) {
+	// Slow inputs let prefetching run ahead as far as it can
+	time.Sleep(2 * time.Millisecond)
+	f.pending.Add(-1)
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	*f.order = append(*f.order, f.name)
+	return container, nil
+}
+
+func TestRunInputPrefetchLimited(t *testing.T) {
+	defer SetConcurrency(0)
+	SetConcurrency(2)
+
+	var pending, maxPending atomic.Int32
+	var mu sync.Mutex
+	order := make([]string, 0)
+	want := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+
+	instance, _ := NewInstance()
+	for _, name := range want {
+		instance.AddInput(&fakePrefetchInput{
+			name:     name,
+			pending:  &pending,
+			maxValue: &maxPending,
+			mu:       &mu,
+			order:    &order,
+		})
+	}
+
+	if err := instance.RunInput(NewContainer()); err != nil {
+		t.Fatalf("RunInput() error = %v", err)
+	}
+
+	if !slices.Equal(order, want) {
+		t.Errorf("inputs ran in order %v, want %v", order, want)
+	}
+	if got := maxPending.Load(); got > 2 {
+		t.Errorf("%d prefetched inputs kept at the same time, want at most 2", got)
+	}
+}
+
+type fakeFileOutput struct {
+	fakeOutput
+	dir     string
+	running *atomic.Int32
+	overlap *atomic.Bool
+}
+
+func (f *fakeFileOutput) OutputFiles() []string {
+	return []string{filepath.Join(f.dir, f.name+".txt")}
+}
+
+func (f *fakeFileOutput) Output(container Container) error {
+	if f.running.Add(1) > 1 {
+		f.overlap.Store(true)
+	}
+	defer f.running.Add(-1)
+	return f.fakeOutput.Output(container)
+}
+
+func TestRunOutputSameDirInOrder(t *testing.T) {
+	defer SetConcurrency(0)
+	SetConcurrency(8)
+
+	var mu sync.Mutex
+	order := make([]string, 0)
+	running := map[string]*atomic.Int32{"a": new(atomic.Int32), "b": new(atomic.Int32)}
+	var overlap atomic.Bool
+
+	instance, _ := NewInstance()
+	outputs := []struct{ name, dir string }{
+		{"a1", "a"}, {"b1", "b"}, {"a2", "a"}, {"a3", "a/../a"}, {"b2", "b"}, {"a4", "a"},
+	}
+	for _, output := range outputs {
+		instance.AddOutput(&fakeFileOutput{
+			fakeOutput: fakeOutput{name: output.name, mu: &mu, order: &order},
+			dir:        output.dir,
+			running:    running[filepath.Clean(output.dir)],
+			overlap:    &overlap,
+		})
+	}
+
+	if err := instance.RunOutput(NewContainer()); err != nil {
+		t.Fatalf("RunOutput() error = %v", err)
+	}
+
+	if overlap.Load() {
+		t.Error("outputs writing in the same directory ran at the same time")
+	}
+	for _, prefix := range []string{"a", "b"} {
+		got := slices.DeleteFunc(slices.Clone(order), func(name string) bool {
+			return name[:1] != prefix
+		})
+		want := make([]string, 0)
+		for _, output := range outputs {
+			if output.name[:1] == prefix {
+				want = append(want, output.name)
+			}
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("outputs in directory %s ran in order %v, want %v", prefix, got, want)
+		}
+	}
+}
+
+func TestGroupOutputs(t *testing.T) {
+	newOutput := func(name, dir string, stdout bool) OutputConverter {
+		if dir == "" {
+			return &fakeOutput{name: name, stdout: stdout}
+		}
+		return &fakeFileOutput{fakeOutput: fakeOutput{name: name}, dir: dir}
+	}
+
+	outputs := []OutputConverter{
+		newOutput("file1", "x", false),
+		newOutput("stdout1", "", true),
+		newOutput("file2", "y", false),
+		newOutput("none", "", false),
+		newOutput("stdout2", "", true),
+		newOutput("file3", "x", false),
+	}
+
+	got := make([][]string, 0)
+	for _, group := range groupOutputs(outputs) {
+		names := make([]string, 0, len(group))
+		for _, oc := range group {
+			names = append(names, oc.GetType())
+		}
+		got = append(got, names)
+	}
+
+	want := [][]string{{"file1", "file3"}, {"stdout1", "stdout2"}, {"file2"}, {"none"}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("groupOutputs() = %v, want %v", got, want)
+	}
+}
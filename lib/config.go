@@ -25,11 +25,13 @@ func RegisterInputConfigCreator(id string, fn inputConfigCreator) error {
 	return nil
 }
 
-func createInputConfig(id string, action Action, data json.RawMessage) (InputConverter, error) {
+// CreateInputConverter creates an input converter of the registered type id
+// from its args in JSON format, like an input in config file.
+func CreateInputConverter(id string, action Action, data json.RawMessage) (InputConverter, error) {
 	id = strings.ToLower(id)
 	fn, found := inputConfigCreatorCache[id]
 	if !found {
-		return nil, errors.New("unknown config type")
+		return nil, fmt.Errorf("unknown config type %s", id)
 	}
 	return fn(action, data)
 }
@@ -43,24 +45,130 @@ func RegisterOutputConfigCreator(id string, fn outputConfigCreator) error {
 	return nil
 }
 
-func createOutputConfig(id string, action Action, data json.RawMessage) (OutputConverter, error) {
+// CreateOutputConverter creates an output converter of the registered type id
+// from its args in JSON format, like an output in config file.
+func CreateOutputConverter(id string, action Action, data json.RawMessage) (OutputConverter, error) {
 	id = strings.ToLower(id)
 	fn, found := outputConfigCreatorCache[id]
 	if !found {
-		return nil, errors.New("unknown config type")
+		return nil, fmt.Errorf("unknown config type %s", id)
 	}
 	return fn(action, data)
 }
 
 type config struct {
-	Input  []*inputConvConfig  `json:"input"`
-	Output []*outputConvConfig `json:"output"`
+	Input       inputConvConfigs  `json:"input"`
+	Output      outputConvConfigs `json:"output"`
+	Concurrency int               `json:"concurrency"`
+	HTTP        *HTTPConfig       `json:"http"`
+
+	// Positions of inputs and outputs in config files to report errors with
+	inputPos  []string
+	outputPos []string
+}
+
+// UnmarshalJSON reports errors of both inputs and outputs.
+func (c *config) UnmarshalJSON(data []byte) error {
+	var temp struct {
+		Input       json.RawMessage `json:"input"`
+		Output      json.RawMessage `json:"output"`
+		Concurrency int             `json:"concurrency"`
+		HTTP        *HTTPConfig     `json:"http"`
+	}
+
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+
+	c.Concurrency = temp.Concurrency
+	c.HTTP = temp.HTTP
+
+	errs := make([]error, 0, 2)
+	if len(temp.Input) > 0 {
+		if err := c.Input.unmarshalJSON(temp.Input, c.inputPos); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(temp.Output) > 0 {
+		if err := c.Output.unmarshalJSON(temp.Output, c.outputPos); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+type inputConvConfigs []*inputConvConfig
+
+// UnmarshalJSON reports errors of all inputs with their index and type,
+// instead of only the first one.
+func (c *inputConvConfigs) UnmarshalJSON(data []byte) error {
+	return c.unmarshalJSON(data, nil)
+}
+
+// unmarshalJSON reports errors of inputs also with their positions in config files, if known.
+func (c *inputConvConfigs) unmarshalJSON(data []byte, positions []string) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+
+	positions = padPositions(positions, len(raws))
+	errs := make([]error, 0)
+	for idx, raw := range raws {
+		input := new(inputConvConfig)
+		if err := input.UnmarshalJSON(raw); err != nil {
+			errs = append(errs, withPosition(positions[idx], fmt.Errorf("input #%d (type %s): %w", idx+1, getConvConfigType(raw), err)))
+			continue
+		}
+		*c = append(*c, input)
+	}
+
+	return errors.Join(errs...)
+}
+
+type outputConvConfigs []*outputConvConfig
+
+// UnmarshalJSON reports errors of all outputs with their index and type,
+// instead of only the first one.
+func (c *outputConvConfigs) UnmarshalJSON(data []byte) error {
+	return c.unmarshalJSON(data, nil)
+}
+
+// unmarshalJSON reports errors of outputs also with their positions in config files, if known.
+func (c *outputConvConfigs) unmarshalJSON(data []byte, positions []string) error {
+	var raws []json.RawMessage
+	if err := json.Unmarshal(data, &raws); err != nil {
+		return err
+	}
+
+	positions = padPositions(positions, len(raws))
+	errs := make([]error, 0)
+	for idx, raw := range raws {
+		output := new(outputConvConfig)
+		if err := output.UnmarshalJSON(raw); err != nil {
+			errs = append(errs, withPosition(positions[idx], fmt.Errorf("output #%d (type %s): %w", idx+1, getConvConfigType(raw), err)))
+			continue
+		}
+		*c = append(*c, output)
+	}
+
+	return errors.Join(errs...)
+}
+
+func getConvConfigType(data []byte) string {
+	var temp struct {
+		Type string `json:"type"`
+	}
+	json.Unmarshal(data, &temp)
+	return temp.Type
 }
 
 type inputConvConfig struct {
 	iType     string
 	action    Action
 	converter InputConverter
+	args      json.RawMessage
 }
 
 func (i *inputConvConfig) UnmarshalJSON(data []byte) error {
@@ -78,7 +186,22 @@ func (i *inputConvConfig) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("invalid action %s in type %s", temp.Action, temp.Type)
 	}
 
-	config, err := createInputConfig(temp.Type, temp.Action, temp.Args)
+	if strict {
+		if err := checkFields(data, "field", "type", "action", "args"); err != nil {
+			return err
+		}
+		// Converters registered without schema cannot be checked
+		if schema, found := GetInputConverterSchema(temp.Type); found {
+			if !schema.SupportsAction(temp.Action) {
+				return fmt.Errorf("type %s does not support action %s", temp.Type, temp.Action)
+			}
+			if err := schema.checkArgs(temp.Args); err != nil {
+				return err
+			}
+		}
+	}
+
+	config, err := CreateInputConverter(temp.Type, temp.Action, temp.Args)
 	if err != nil {
 		return err
 	}
@@ -86,6 +209,7 @@ func (i *inputConvConfig) UnmarshalJSON(data []byte) error {
 	i.iType = config.GetType()
 	i.action = config.GetAction()
 	i.converter = config
+	i.args = temp.Args
 
 	return nil
 }
@@ -94,6 +218,7 @@ type outputConvConfig struct {
 	iType     string
 	action    Action
 	converter OutputConverter
+	args      json.RawMessage
 }
 
 func (i *outputConvConfig) UnmarshalJSON(data []byte) error {
@@ -115,7 +240,22 @@ func (i *outputConvConfig) UnmarshalJSON(data []byte) error {
 		return fmt.Errorf("invalid action %s in type %s", temp.Action, temp.Type)
 	}
 
-	config, err := createOutputConfig(temp.Type, temp.Action, temp.Args)
+	if strict {
+		if err := checkFields(data, "field", "type", "action", "args"); err != nil {
+			return err
+		}
+		// Converters registered without schema cannot be checked
+		if schema, found := GetOutputConverterSchema(temp.Type); found {
+			if !schema.SupportsAction(temp.Action) {
+				return fmt.Errorf("type %s does not support action %s", temp.Type, temp.Action)
+			}
+			if err := schema.checkArgs(temp.Args); err != nil {
+				return err
+			}
+		}
+	}
+
+	config, err := CreateOutputConverter(temp.Type, temp.Action, temp.Args)
 	if err != nil {
 		return err
 	}
@@ -123,6 +263,7 @@ func (i *outputConvConfig) UnmarshalJSON(data []byte) error {
 	i.iType = config.GetType()
 	i.action = config.GetAction()
 	i.converter = config
+	i.args = temp.Args
 
 	return nil
 }
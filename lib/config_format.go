@@ -0,0 +1,307 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/tailscale/hujson"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	configFormatJSON = "json"
+	configFormatYAML = "yaml"
+	configFormatTOML = "toml"
+)
+
+var tomlKeyValueRegexp = regexp.MustCompile(`^[A-Za-z0-9_."'-]+\s*=`)
+
+// detectConfigFormat detects the format of the config file by the extension of uri,
+// or else by its content.
+func detectConfigFormat(uri string, content []byte) string {
+	// Strip query and fragment of remote URL
+	if index := strings.IndexAny(uri, "?#"); index >= 0 && isRemoteURI(uri) {
+		uri = uri[:index]
+	}
+
+	switch strings.ToLower(path.Ext(uri)) {
+	case ".json", ".jsonc":
+		return configFormatJSON
+	case ".yaml", ".yml":
+		return configFormatYAML
+	case ".toml":
+		return configFormatTOML
+	}
+
+	// Check the first line other than empty lines and comments
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "{"), strings.HasPrefix(line, "//"), strings.HasPrefix(line, "/*"):
+			return configFormatJSON
+		case strings.HasPrefix(line, "["), tomlKeyValueRegexp.MatchString(line):
+			return configFormatTOML
+		default:
+			return configFormatYAML
+		}
+	}
+
+	return configFormatJSON
+}
+
+// convertConfigToJSON converts content of the config file to standard JSON.
+// Errors of parsing point to the line of the original content.
+func convertConfigToJSON(uri string, content []byte) ([]byte, error) {
+	switch detectConfigFormat(uri, content) {
+	case configFormatYAML:
+		var value any
+		// Errors of yaml contain the line already
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return nil, err
+		}
+		return json.Marshal(convertYAMLValue(value))
+
+	case configFormatTOML:
+		var value map[string]any
+		if err := toml.Unmarshal(content, &value); err != nil {
+			var decodeErr *toml.DecodeError
+			if errors.As(err, &decodeErr) {
+				row, column := decodeErr.Position()
+				return nil, fmt.Errorf("toml: line %d, column %d: %s", row, column, strings.TrimPrefix(decodeErr.Error(), "toml: "))
+			}
+			return nil, err
+		}
+		return json.Marshal(value)
+
+	default:
+		// Support JSON with comments and trailing commas,
+		// and errors of hujson contain the line already
+		value, err := hujson.Parse(content)
+		if err != nil {
+			return nil, err
+		}
+		value.Standardize()
+		return value.Pack(), nil
+	}
+}
+
+// convertYAMLValue converts maps with keys of any type decoded by yaml
+// to maps with string keys, which can be encoded to JSON.
+func convertYAMLValue(value any) any {
+	switch value := value.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(value))
+		for key, item := range value {
+			m[fmt.Sprint(key)] = convertYAMLValue(item)
+		}
+		return m
+
+	case []any:
+		for i, item := range value {
+			value[i] = convertYAMLValue(item)
+		}
+		return value
+	}
+
+	return value
+}
+
+// Keys of elements located in config files
+const (
+	configKeyInput       = "input"
+	configKeyOutput      = "output"
+	configKeyInputGroups = "inputGroups"
+)
+
+var tomlArrayTableRegexp = regexp.MustCompile(`^\s*\[\[\s*(.+?)\s*\]\]`)
+
+// locateConfigElements returns the lines of inputs, outputs and inputs of
+// input groups in the config file, keyed by "input", "output" and
+// "inputGroups.<name>", to report errors of them with their lines.
+// Elements are located in JSON by parsing, in YAML by indentation of block
+// sequences, and in TOML by headers of arrays of tables. Elements written
+// in other styles are not located, and the lines of them may be missing.
+func locateConfigElements(uri string, content []byte) map[string][]int {
+	switch detectConfigFormat(uri, content) {
+	case configFormatYAML:
+		return locateYAMLElements(content)
+	case configFormatTOML:
+		return locateTOMLElements(content)
+	default:
+		return locateJSONElements(content)
+	}
+}
+
+func locateJSONElements(content []byte) map[string][]int {
+	lines := make(map[string][]int)
+	value, err := hujson.Parse(content)
+	if err != nil {
+		return lines
+	}
+
+	lineOf := func(offset int) int {
+		return bytes.Count(content[:offset], []byte("\n")) + 1
+	}
+	elementLines := func(value *hujson.Value) []int {
+		array, ok := value.Value.(*hujson.Array)
+		if !ok {
+			return nil
+		}
+		result := make([]int, 0, len(array.Elements))
+		for _, element := range array.Elements {
+			result = append(result, lineOf(element.StartOffset))
+		}
+		return result
+	}
+
+	root, ok := value.Value.(*hujson.Object)
+	if !ok {
+		return lines
+	}
+	for _, member := range root.Members {
+		// Fields are matched case-insensitively when unmarshaling
+		name := member.Name.Value.(hujson.Literal).String()
+		switch {
+		case strings.EqualFold(name, configKeyInput):
+			lines[configKeyInput] = elementLines(&member.Value)
+		case strings.EqualFold(name, configKeyOutput):
+			lines[configKeyOutput] = elementLines(&member.Value)
+		case strings.EqualFold(name, configKeyInputGroups):
+			groups, ok := member.Value.Value.(*hujson.Object)
+			if !ok {
+				continue
+			}
+			for _, group := range groups.Members {
+				groupName := group.Name.Value.(hujson.Literal).String()
+				lines[configKeyInputGroups+"."+groupName] = elementLines(&group.Value)
+			}
+		}
+	}
+
+	return lines
+}
+
+// yamlLine is a line of YAML other than empty lines and comments.
+type yamlLine struct {
+	number int
+	indent int
+	text   string
+}
+
+func locateYAMLElements(content []byte) map[string][]int {
+	yamlLines := make([]yamlLine, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), " \t\r")
+		trimmed := strings.TrimLeft(text, " ")
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		yamlLines = append(yamlLines, yamlLine{number: number, indent: len(text) - len(trimmed), text: trimmed})
+	}
+
+	lines := make(map[string][]int)
+	for name, block := range yamlMappingBlocks(yamlLines) {
+		switch {
+		case strings.EqualFold(name, configKeyInput):
+			lines[configKeyInput] = yamlSequenceLines(block)
+		case strings.EqualFold(name, configKeyOutput):
+			lines[configKeyOutput] = yamlSequenceLines(block)
+		case strings.EqualFold(name, configKeyInputGroups):
+			for groupName, groupBlock := range yamlMappingBlocks(block) {
+				lines[configKeyInputGroups+"."+groupName] = yamlSequenceLines(groupBlock)
+			}
+		}
+	}
+
+	return lines
+}
+
+// yamlMappingBlocks returns the lines of the value of each key of the block mapping in lines.
+func yamlMappingBlocks(lines []yamlLine) map[string][]yamlLine {
+	blocks := make(map[string][]yamlLine)
+	if len(lines) == 0 {
+		return blocks
+	}
+
+	indent := lines[0].indent
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if line.indent != indent || strings.HasPrefix(line.text, "-") {
+			continue
+		}
+		key, _, found := strings.Cut(line.text, ":")
+		if !found {
+			continue
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"'`)
+
+		// The value is the lines indented more than the key,
+		// or a block sequence indented the same as the key
+		end := i + 1
+		for ; end < len(lines); end++ {
+			next := lines[end]
+			if next.indent < indent || (next.indent == indent && !strings.HasPrefix(next.text, "- ") && next.text != "-") {
+				break
+			}
+		}
+		blocks[key] = lines[i+1 : end]
+		i = end - 1
+	}
+
+	return blocks
+}
+
+// yamlSequenceLines returns the lines of the items of the block sequence in lines.
+func yamlSequenceLines(lines []yamlLine) []int {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	indent := lines[0].indent
+	result := make([]int, 0)
+	for _, line := range lines {
+		if line.indent == indent && (strings.HasPrefix(line.text, "- ") || line.text == "-") {
+			result = append(result, line.number)
+		}
+	}
+	return result
+}
+
+func locateTOMLElements(content []byte) map[string][]int {
+	lines := make(map[string][]int)
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for number := 1; scanner.Scan(); number++ {
+		matches := tomlArrayTableRegexp.FindStringSubmatch(scanner.Text())
+		if matches == nil {
+			continue
+		}
+
+		keys := strings.Split(matches[1], ".")
+		for i, key := range keys {
+			keys[i] = strings.Trim(strings.TrimSpace(key), `"'`)
+		}
+
+		switch {
+		case len(keys) == 1 && keys[0] == configKeyInput:
+			lines[configKeyInput] = append(lines[configKeyInput], number)
+		case len(keys) == 1 && keys[0] == configKeyOutput:
+			lines[configKeyOutput] = append(lines[configKeyOutput], number)
+		case len(keys) == 2 && keys[0] == configKeyInputGroups:
+			key := configKeyInputGroups + "." + keys[1]
+			lines[key] = append(lines[key], number)
+		}
+	}
+
+	return lines
+}
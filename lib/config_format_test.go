@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDetectConfigFormat(t *testing.T) {
+	tests := []struct {
+		uri     string
+		content string
+		want    string
+	}{
+		{uri: "config.json", content: "input: []", want: configFormatJSON},
+		{uri: "config.jsonc", want: configFormatJSON},
+		{uri: "config.YAML", content: `{"input": []}`, want: configFormatYAML},
+		{uri: "config.yml", want: configFormatYAML},
+		{uri: "config.toml", want: configFormatTOML},
+		{uri: "https://example.com/config.yaml?token=secret#top", want: configFormatYAML},
+		{uri: "https://example.com/download?file=config.toml", content: `{"input": []}`, want: configFormatJSON},
+		{content: "\n# comment\n{\"input\": []}", want: configFormatJSON},
+		{content: "// comment\n{}", want: configFormatJSON},
+		{content: "# comment\n\ninput:\n  - type: text", want: configFormatYAML},
+		{content: "[[input]]\ntype = \"text\"", want: configFormatTOML},
+		{content: "concurrency = 4", want: configFormatTOML},
+		{content: "", want: configFormatJSON},
+	}
+
+	for _, tt := range tests {
+		if got := detectConfigFormat(tt.uri, []byte(tt.content)); got != tt.want {
+			t.Errorf("detectConfigFormat(%q, %q) = %s, want %s", tt.uri, tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestConvertConfigToJSON(t *testing.T) {
+	want := `{"concurrency":2,"input":[{"action":"add","args":{"wantedList":["cn"]},"type":"text"}]}`
+	tests := []struct {
+		uri     string
+		content string
+	}{
+		{
+			uri: "config.json",
+			content: `{
+				// comment
+				"concurrency": 2,
+				"input": [{"action": "add", "args": {"wantedList": ["cn",],}, "type": "text"},],
+			}`,
+		},
+		{
+			uri: "config.yaml",
+			content: `
+concurrency: 2
+input:
+  - type: text
+    action: add
+    args:
+      wantedList: [cn]
+`,
+		},
+		{
+			uri: "config.toml",
+			content: `
+concurrency = 2
+
+[[input]]
+type = "text"
+action = "add"
+args = { wantedList = ["cn"] }
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		content, err := convertConfigToJSON(tt.uri, []byte(tt.content))
+		if err != nil {
+			t.Fatalf("convertConfigToJSON(%s) error = %v", tt.uri, err)
+		}
+		var value any
+		if err := json.Unmarshal(content, &value); err != nil {
+			t.Fatal(err)
+		}
+		if got := string(must(json.Marshal(value))); got != want {
+			t.Errorf("convertConfigToJSON(%s) = %s, want %s", tt.uri, got, want)
+		}
+	}
+}
+
+func TestLocateConfigElements(t *testing.T) {
+	tests := []struct {
+		uri     string
+		content string
+		want    map[string][]int
+	}{
+		{
+			uri: "config.json",
+			content: `{
+  // comment
+  "inputGroups": {
+    "cn": [
+      {"type": "a"},
+      {"type": "b"}
+    ]
+  },
+  "Input": [{"group": "cn"},
+    {"type": "c"}
+  ],
+  "output": [
+    {
+      "type": "d"
+    }
+  ]
+}`,
+			want: map[string][]int{"inputGroups.cn": {5, 6}, "input": {9, 10}, "output": {13}},
+		},
+		{
+			uri: "config.yaml",
+			content: `# comment
+inputGroups:
+  cn:
+    - type: a
+
+    - type: b
+input:
+- group: cn
+- type: c
+  args:
+    list:
+      - x
+output:
+  - type: d
+`,
+			want: map[string][]int{"inputGroups.cn": {4, 6}, "input": {8, 9}, "output": {14}},
+		},
+		{
+			uri: "config.toml",
+			content: `concurrency = 1
+
+[[inputGroups.cn]]
+type = "a"
+
+[[ inputGroups."cn" ]]
+type = "b"
+
+[[input]]
+group = "cn"
+
+[[input]]
+type = "c"
+
+[[output]]
+type = "d"
+`,
+			want: map[string][]int{"inputGroups.cn": {3, 6}, "input": {9, 12}, "output": {15}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := locateConfigElements(tt.uri, []byte(tt.content))
+		for key, lines := range tt.want {
+			if !slices.Equal(got[key], lines) {
+				t.Errorf("locateConfigElements(%s)[%s] = %v, want %v", tt.uri, key, got[key], lines)
+			}
+		}
+	}
+}
+
+func TestInitConfigErrorPositions(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"config.json": `{
+  "include": ["base.yaml"],
+  "inputGroups": {
+    "g": [
+      {"type": "nope-group", "action": "add"}
+    ]
+  },
+  "input": [
+    {"group": "g"},
+    {"type": "nope-json", "action": "add"}
+  ],
+  "output": [
+    {"type": "nope-out", "action": "output"}
+  ]
+}`,
+		"base.yaml": `input:
+  - type: nope-yaml
+    action: add
+`,
+		"other.toml": `[[input]]
+type = "nope-toml"
+action = "add"
+`,
+	})
+
+	instance := must(NewInstance())
+	err := instance.InitConfig(filepath.Join(dir, "config.json"))
+	if err == nil {
+		t.Fatal("InitConfig() error = nil, want errors of unknown types")
+	}
+	for _, want := range []string{
+		filepath.Join(dir, "base.yaml") + ":2: input #1 (type nope-yaml)",
+		filepath.Join(dir, "config.json") + ":5: input #2 (type nope-group)",
+		filepath.Join(dir, "config.json") + ":10: input #3 (type nope-json)",
+		filepath.Join(dir, "config.json") + ":13: output #1 (type nope-out)",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("InitConfig() error = %v, want error containing %q", err, want)
+		}
+	}
+
+	err = must(NewInstance()).InitConfig(filepath.Join(dir, "other.toml"))
+	if want := filepath.Join(dir, "other.toml") + ":1: input #1 (type nope-toml)"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("InitConfig() error = %v, want error containing %q", err, want)
+	}
+
+	// Content without uri has lines only
+	err = must(NewInstance()).InitConfigFromBytes([]byte("\n{\"input\": [{\"type\": \"nope\"}]}"))
+	if want := "line 2: input #1 (type nope)"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("InitConfigFromBytes() error = %v, want error containing %q", err, want)
+	}
+
+	// Missing input groups are reported at the reference
+	err = must(NewInstance()).InitConfigFromBytes([]byte("input:\n  - group: missing\n"))
+	if want := "line 2: input group missing not found"; err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("InitConfigFromBytes() error = %v, want error containing %q", err, want)
+	}
+}
@@ -2,8 +2,11 @@ package lib
 
 import (
 	"fmt"
+	"math/big"
 	"net/netip"
+	"slices"
 	"strings"
+	"sync"
 
 	"go4.org/netipx"
 )
@@ -13,12 +16,36 @@ type Container interface {
 	Len() int
 	Add(entry *Entry, opts ...IgnoreIPOption) error
 	Remove(entry *Entry, rCase CaseRemove, opts ...IgnoreIPOption) error
+	Copy(name string, sources []string, opts ...IgnoreIPOption) error
 	Loop() <-chan *Entry
 	Lookup(ipOrCidr string, searchList ...string) ([]string, bool, error)
+	LookupDetail(ipOrCidr string, searchList ...string) ([]*LookupResult, error)
+	LookupOverlap(ipOrCidr string, searchList ...string) ([]*OverlapResult, error)
+	Freeze() error
+}
+
+// LookupResult is a list matching the IP or CIDR looked up.
+// For an IP or a CIDR fully contained in the list, Prefix is the prefix of the list containing it.
+// For a CIDR partially overlapping the list, Prefix is the first prefix of the list within it.
+type LookupResult struct {
+	List        string       `json:"list"`
+	Prefix      netip.Prefix `json:"prefix"`
+	Containment Containment  `json:"containment"`
+}
+
+// OverlapResult is a list intersecting the IP or CIDR looked up,
+// with the intersected prefixes and the fraction of addresses of the CIDR in the list.
+type OverlapResult struct {
+	List         string         `json:"list"`
+	Prefixes     []netip.Prefix `json:"prefixes"`
+	AddressCount *big.Int       `json:"addressCount"`
+	Fraction     float64        `json:"fraction"`
 }
 
 type container struct {
+	mu      sync.RWMutex
 	entries map[string]*Entry
+	index   *lookupIndex // built when frozen
 }
 
 func NewContainer() Container {
@@ -27,11 +54,35 @@ func NewContainer() Container {
 	}
 }
 
+// NewContainerFromMap returns a container with an entry for each list in lists,
+// whose prefixes may be of any type supported by Entry.AddPrefix.
+func NewContainerFromMap[T any](lists map[string][]T) (Container, error) {
+	container := NewContainer()
+	for name, prefixes := range lists {
+		entry := NewEntry(name)
+		for _, prefix := range prefixes {
+			if err := entry.AddPrefix(prefix); err != nil {
+				return nil, err
+			}
+		}
+		if err := container.Add(entry); err != nil {
+			return nil, err
+		}
+	}
+	return container, nil
+}
+
 func (c *container) isValid() bool {
 	return c.entries != nil
 }
 
 func (c *container) GetEntry(name string) (*Entry, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.getEntry(name)
+}
+
+func (c *container) getEntry(name string) (*Entry, bool) {
 	if !c.isValid() {
 		return nil, false
 	}
@@ -43,16 +94,27 @@ func (c *container) GetEntry(name string) (*Entry, bool) {
 }
 
 func (c *container) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	if !c.isValid() {
 		return 0
 	}
 	return len(c.entries)
 }
 
+// Loop iterates over a snapshot of the entries, so it is safe to be used by
+// concurrent readers, and to modify the container while iterating.
 func (c *container) Loop() <-chan *Entry {
+	c.mu.RLock()
+	entries := make([]*Entry, 0, len(c.entries))
+	for _, val := range c.entries {
+		entries = append(entries, val)
+	}
+	c.mu.RUnlock()
+
 	ch := make(chan *Entry, 300)
 	go func() {
-		for _, val := range c.entries {
+		for _, val := range entries {
 			ch <- val
 		}
 		close(ch)
@@ -60,7 +122,36 @@ func (c *container) Loop() <-chan *Entry {
 	return ch
 }
 
+// Freeze builds a lookup index of the container to speed up Lookup.
+// The container cannot be modified any more once frozen,
+// and neither should the entries in it be.
+func (c *container) Freeze() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.index != nil {
+		return nil
+	}
+
+	index, err := newLookupIndex(c.entries)
+	if err != nil {
+		return err
+	}
+	c.index = index
+
+	return nil
+}
+
 func (c *container) Add(entry *Entry, opts ...IgnoreIPOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index != nil {
+		return ErrContainerFrozen
+	}
+	return c.add(entry, opts...)
+}
+
+func (c *container) add(entry *Entry, opts ...IgnoreIPOption) error {
 	var ignoreIPType IPType
 	for _, opt := range opts {
 		if opt != nil {
@@ -69,7 +160,7 @@ func (c *container) Add(entry *Entry, opts ...IgnoreIPOption) error {
 	}
 
 	name := entry.GetName()
-	val, found := c.GetEntry(name)
+	val, found := c.getEntry(name)
 
 	switch found {
 	case true:
@@ -108,6 +199,7 @@ func (c *container) Add(entry *Entry, opts ...IgnoreIPOption) error {
 			val.ipv4Builder.AddSet(ipv4set)
 			val.ipv6Builder.AddSet(ipv6set)
 		}
+		val.resetIPSet()
 
 	case false:
 		switch ignoreIPType {
@@ -123,8 +215,14 @@ func (c *container) Add(entry *Entry, opts ...IgnoreIPOption) error {
 }
 
 func (c *container) Remove(entry *Entry, rCase CaseRemove, opts ...IgnoreIPOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index != nil {
+		return ErrContainerFrozen
+	}
+
 	name := entry.GetName()
-	val, found := c.GetEntry(name)
+	val, found := c.getEntry(name)
 	if !found {
 		return fmt.Errorf("entry %s not found", name)
 	}
@@ -174,6 +272,7 @@ func (c *container) Remove(entry *Entry, rCase CaseRemove, opts ...IgnoreIPOptio
 			val.ipv4Builder.RemoveSet(ipv4set)
 			val.ipv6Builder.RemoveSet(ipv6set)
 		}
+		val.resetIPSet()
 
 	case CaseRemoveEntry:
 		switch ignoreIPType {
@@ -184,6 +283,7 @@ func (c *container) Remove(entry *Entry, rCase CaseRemove, opts ...IgnoreIPOptio
 		default:
 			delete(c.entries, name)
 		}
+		val.resetIPSet()
 
 	default:
 		return fmt.Errorf("unknown remove case %d", rCase)
@@ -192,44 +292,282 @@ func (c *container) Remove(entry *Entry, rCase CaseRemove, opts ...IgnoreIPOptio
 	return nil
 }
 
+// Copy adds the IP addresses of all source entries to the entry with the given name,
+// which will be created if it does not exist yet.
+func (c *container) Copy(name string, sources []string, opts ...IgnoreIPOption) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.index != nil {
+		return ErrContainerFrozen
+	}
+
+	entry := NewEntry(name)
+
+	for _, source := range sources {
+		val, found := c.getEntry(source)
+		if !found {
+			return fmt.Errorf("entry %s not found", strings.ToUpper(strings.TrimSpace(source)))
+		}
+
+		if val.hasIPv4Builder() {
+			ipv4set, err := val.ipv4Builder.IPSet()
+			if err != nil {
+				return err
+			}
+			if !entry.hasIPv4Builder() {
+				entry.ipv4Builder = new(netipx.IPSetBuilder)
+			}
+			entry.ipv4Builder.AddSet(ipv4set)
+		}
+
+		if val.hasIPv6Builder() {
+			ipv6set, err := val.ipv6Builder.IPSet()
+			if err != nil {
+				return err
+			}
+			if !entry.hasIPv6Builder() {
+				entry.ipv6Builder = new(netipx.IPSetBuilder)
+			}
+			entry.ipv6Builder.AddSet(ipv6set)
+		}
+	}
+
+	var ignoreIPType IPType
+	for _, opt := range opts {
+		if opt != nil {
+			ignoreIPType = opt()
+		}
+	}
+
+	switch ignoreIPType {
+	case IPv4:
+		entry.ipv4Builder = nil
+	case IPv6:
+		entry.ipv6Builder = nil
+	}
+
+	if !entry.hasIPv4Builder() && !entry.hasIPv6Builder() {
+		return fmt.Errorf("entry %s has no prefix to copy", entry.GetName())
+	}
+
+	return c.add(entry, opts...)
+}
+
 func (c *container) Lookup(ipOrCidr string, searchList ...string) ([]string, bool, error) {
+	addrOrPrefix, iptype, err := parseLookupTarget(ipOrCidr)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return c.lookup(addrOrPrefix, iptype, searchList...)
+}
+
+// LookupDetail returns the lists containing or overlapping the IP or CIDR,
+// with the matched prefix of each list, sorted by list name.
+func (c *container) LookupDetail(ipOrCidr string, searchList ...string) ([]*LookupResult, error) {
+	addrOrPrefix, iptype, err := parseLookupTarget(ipOrCidr)
+	if err != nil {
+		return nil, err
+	}
+
+	var query netip.Prefix
+	switch addrOrPrefix := addrOrPrefix.(type) {
+	case netip.Prefix:
+		query = addrOrPrefix.Masked()
+	case netip.Addr:
+		query = netip.PrefixFrom(addrOrPrefix, addrOrPrefix.BitLen())
+	}
+
+	searchMap := newSearchMap(searchList)
+
+	result := make([]*LookupResult, 0, 8)
+	for entry := range c.Loop() {
+		if len(searchMap) > 0 && !searchMap[entry.GetName()] {
+			continue
+		}
+
+		if err := entry.buildIPSet(); err != nil {
+			return nil, err
+		}
+
+		var ipset *netipx.IPSet
+		switch iptype {
+		case IPv4:
+			ipset = entry.ipv4Set
+		case IPv6:
+			ipset = entry.ipv6Set
+		}
+
+		if ipset == nil || !ipset.OverlapsPrefix(query) {
+			continue
+		}
+
+		prefix, containment := matchPrefix(ipset.Prefixes(), query)
+		result = append(result, &LookupResult{
+			List:        entry.GetName(),
+			Prefix:      prefix,
+			Containment: containment,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b *LookupResult) int {
+		return strings.Compare(a.List, b.List)
+	})
+
+	return result, nil
+}
+
+// LookupOverlap returns the lists intersecting the IP or CIDR, with the intersected prefixes,
+// sorted by the fraction of addresses of the CIDR in each list in descending order.
+func (c *container) LookupOverlap(ipOrCidr string, searchList ...string) ([]*OverlapResult, error) {
+	addrOrPrefix, iptype, err := parseLookupTarget(ipOrCidr)
+	if err != nil {
+		return nil, err
+	}
+
+	var query netip.Prefix
+	switch addrOrPrefix := addrOrPrefix.(type) {
+	case netip.Prefix:
+		query = addrOrPrefix.Masked()
+	case netip.Addr:
+		query = netip.PrefixFrom(addrOrPrefix, addrOrPrefix.BitLen())
+	}
+	total := CountAddresses([]netip.Prefix{query})
+
+	searchMap := newSearchMap(searchList)
+
+	result := make([]*OverlapResult, 0, 8)
+	for entry := range c.Loop() {
+		if len(searchMap) > 0 && !searchMap[entry.GetName()] {
+			continue
+		}
+
+		if err := entry.buildIPSet(); err != nil {
+			return nil, err
+		}
+
+		var ipset *netipx.IPSet
+		switch iptype {
+		case IPv4:
+			ipset = entry.ipv4Set
+		case IPv6:
+			ipset = entry.ipv6Set
+		}
+
+		if ipset == nil || !ipset.OverlapsPrefix(query) {
+			continue
+		}
+
+		var builder netipx.IPSetBuilder
+		builder.AddPrefix(query)
+		builder.Intersect(ipset)
+		intersection, err := builder.IPSet()
+		if err != nil {
+			return nil, err
+		}
+
+		prefixes := intersection.Prefixes()
+		count := CountAddresses(prefixes)
+		fraction, _ := new(big.Rat).SetFrac(count, total).Float64()
+
+		result = append(result, &OverlapResult{
+			List:         entry.GetName(),
+			Prefixes:     prefixes,
+			AddressCount: count,
+			Fraction:     fraction,
+		})
+	}
+
+	slices.SortFunc(result, func(a, b *OverlapResult) int {
+		if n := b.AddressCount.Cmp(a.AddressCount); n != 0 {
+			return n
+		}
+		return strings.Compare(a.List, b.List)
+	})
+
+	return result, nil
+}
+
+// matchPrefix returns the prefix in sorted and non-overlapping prefixes
+// containing the query, or else the first one within the query,
+// which must overlap at least one of the prefixes.
+func matchPrefix(prefixes []netip.Prefix, query netip.Prefix) (netip.Prefix, Containment) {
+	// The last prefix starting not after the query
+	i, found := slices.BinarySearchFunc(prefixes, query.Addr(), func(prefix netip.Prefix, target netip.Addr) int {
+		return prefix.Addr().Compare(target)
+	})
+	if !found {
+		i--
+	}
+
+	if i >= 0 {
+		prefix := prefixes[i]
+		if prefix.Bits() <= query.Bits() && prefix.Contains(query.Addr()) {
+			return prefix, ContainmentFull
+		}
+		if prefix.Overlaps(query) {
+			return prefix, ContainmentPartial
+		}
+	}
+
+	return prefixes[i+1], ContainmentPartial
+}
+
+func newSearchMap(searchList []string) map[string]bool {
+	searchMap := make(map[string]bool)
+	for _, name := range searchList {
+		if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+			searchMap[name] = true
+		}
+	}
+	return searchMap
+}
+
+// parseLookupTarget parses an IP or a CIDR to lookup.
+func parseLookupTarget(ipOrCidr string) (any, IPType, error) {
 	switch strings.Contains(ipOrCidr, "/") {
 	case true: // CIDR
 		prefix, err := netip.ParsePrefix(ipOrCidr)
 		if err != nil {
-			return nil, false, err
+			return nil, "", err
 		}
-		addr := prefix.Addr().Unmap()
+		ip := prefix.Addr()
 		switch {
-		case addr.Is4():
-			return c.lookup(prefix, IPv4, searchList...)
-		case addr.Is6():
-			return c.lookup(prefix, IPv6, searchList...)
+		case ip.Is4():
+			return prefix, IPv4, nil
+		case ip.Is4In6():
+			// Compare IPv4-mapped IPv6 CIDRs with IPv4 addresses, as entries store them
+			bits := prefix.Bits()
+			if bits < 96 {
+				return nil, "", ErrInvalidPrefix
+			}
+			return netip.PrefixFrom(ip.Unmap(), bits-96), IPv4, nil
+		default:
+			return prefix, IPv6, nil
 		}
 
-	case false: // IP
+	default: // IP
 		addr, err := netip.ParseAddr(ipOrCidr)
 		if err != nil {
-			return nil, false, err
+			return nil, "", err
 		}
 		addr = addr.Unmap()
-		switch {
-		case addr.Is4():
-			return c.lookup(addr, IPv4, searchList...)
-		case addr.Is6():
-			return c.lookup(addr, IPv6, searchList...)
+		if addr.Is4() {
+			return addr, IPv4, nil
 		}
+		return addr, IPv6, nil
 	}
-
-	return nil, false, nil
 }
 
 func (c *container) lookup(addrOrPrefix any, iptype IPType, searchList ...string) ([]string, bool, error) {
-	searchMap := make(map[string]bool)
-	for _, name := range searchList {
-		if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
-			searchMap[name] = true
-		}
+	searchMap := newSearchMap(searchList)
+
+	c.mu.RLock()
+	index := c.index
+	c.mu.RUnlock()
+	if index != nil {
+		result := index.lookup(addrOrPrefix, iptype, searchMap)
+		return result, len(result) > 0, nil
 	}
 
 	isfound := false
@@ -240,17 +578,21 @@ func (c *container) lookup(addrOrPrefix any, iptype IPType, searchList ...string
 			continue
 		}
 
+		if err := entry.buildIPSet(); err != nil {
+			return nil, false, err
+		}
+
 		var ipset *netipx.IPSet
-		var err error
 		switch iptype {
 		case IPv4:
-			ipset, err = entry.GetIPv4Set()
+			ipset = entry.ipv4Set
 		case IPv6:
-			ipset, err = entry.GetIPv6Set()
+			ipset = entry.ipv6Set
 		}
 
-		if err != nil {
-			return nil, false, err
+		// Entries without IP addresses of the same type cannot contain it
+		if ipset == nil {
+			continue
 		}
 
 		switch addrOrPrefix := addrOrPrefix.(type) {
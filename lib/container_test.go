@@ -0,0 +1,197 @@
+package lib
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"go4.org/netipx"
+)
+
+func TestMatchPrefix(t *testing.T) {
+	prefixes := []netip.Prefix{
+		netip.MustParsePrefix("1.0.0.0/24"),
+		netip.MustParsePrefix("1.0.2.0/23"),
+		netip.MustParsePrefix("1.0.8.0/21"),
+		netip.MustParsePrefix("1.0.32.0/19"),
+	}
+
+	tests := []struct {
+		query       string
+		wantPrefix  string
+		containment Containment
+	}{
+		{query: "1.0.0.0/24", wantPrefix: "1.0.0.0/24", containment: ContainmentFull},
+		{query: "1.0.0.1/32", wantPrefix: "1.0.0.0/24", containment: ContainmentFull},
+		{query: "1.0.0.255/32", wantPrefix: "1.0.0.0/24", containment: ContainmentFull},
+		{query: "1.0.3.0/24", wantPrefix: "1.0.2.0/23", containment: ContainmentFull},
+		{query: "1.0.63.255/32", wantPrefix: "1.0.32.0/19", containment: ContainmentFull},
+		{query: "1.0.0.0/23", wantPrefix: "1.0.0.0/24", containment: ContainmentPartial},
+		{query: "1.0.0.0/16", wantPrefix: "1.0.0.0/24", containment: ContainmentPartial},
+		{query: "1.0.0.0/20", wantPrefix: "1.0.0.0/24", containment: ContainmentPartial},
+		{query: "1.0.4.0/22", wantPrefix: "1.0.8.0/21", containment: ContainmentPartial},
+		{query: "1.0.0.0/8", wantPrefix: "1.0.0.0/24", containment: ContainmentPartial},
+		{query: "0.0.0.0/0", wantPrefix: "1.0.0.0/24", containment: ContainmentPartial},
+	}
+
+	for _, tt := range tests {
+		prefix, containment := matchPrefix(prefixes, netip.MustParsePrefix(tt.query))
+		if prefix.String() != tt.wantPrefix || containment != tt.containment {
+			t.Errorf("matchPrefix(%s) = %s, %s, want %s, %s", tt.query, prefix, containment, tt.wantPrefix, tt.containment)
+		}
+	}
+}
+
+func TestMatchPrefixMatchesLinearScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	base := netip.MustParsePrefix("10.0.0.0/16")
+
+	var builder netipx.IPSetBuilder
+	for i := 0; i < 200; i++ {
+		builder.AddPrefix(randomPrefix(r, base, 20, 32))
+	}
+	ipset, err := builder.IPSet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	prefixes := ipset.Prefixes()
+
+	for i := 0; i < 5000; i++ {
+		query := randomPrefix(r, base, 16, 32)
+		if !ipset.OverlapsPrefix(query) {
+			continue
+		}
+
+		var want netip.Prefix
+		wantContainment := ContainmentPartial
+		for _, prefix := range prefixes {
+			if prefix.Bits() <= query.Bits() && prefix.Contains(query.Addr()) {
+				want, wantContainment = prefix, ContainmentFull
+				break
+			}
+			if query.Contains(prefix.Addr()) {
+				want = prefix
+				break
+			}
+		}
+
+		got, containment := matchPrefix(prefixes, query)
+		if got != want || containment != wantContainment {
+			t.Fatalf("matchPrefix(%s) = %s, %s, want %s, %s", query, got, containment, want, wantContainment)
+		}
+	}
+}
+
+func TestNewContainerFromMap(t *testing.T) {
+	container, err := NewContainerFromMap(map[string][]string{
+		"cn": {"1.0.1.0/24", "2001:250::/35"},
+		"au": {"1.0.0.0/24"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if container.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", container.Len())
+	}
+	entry, found := container.GetEntry("CN")
+	if !found {
+		t.Fatal("entry CN not found")
+	}
+	if prefixes, err := entry.MarshalText(); err != nil || len(prefixes) != 2 {
+		t.Errorf("CN prefixes = %v, error = %v, want 2 prefixes", prefixes, err)
+	}
+
+	if _, err := NewContainerFromMap(map[string][]string{"cn": {"invalid"}}); err == nil {
+		t.Error("NewContainerFromMap() error = nil, want error of invalid prefix")
+	}
+}
+
+func TestContainerCopy(t *testing.T) {
+	container := must(NewContainerFromMap(map[string][]string{
+		"cn": {"1.0.0.0/16", "2001:250::/32"},
+		"hk": {"2.0.0.0/16"},
+	}))
+
+	if err := container.Copy("cn", []string{"hk"}, IgnoreIPv6); err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := container.GetEntry("cn")
+	if prefixes, _ := entry.MarshalText(); len(prefixes) != 3 {
+		t.Errorf("CN prefixes = %v, want merged with HK and IPv6 kept", prefixes)
+	}
+
+	if err := container.Copy("all", []string{"cn", " kr "}); err == nil || err.Error() != "entry KR not found" {
+		t.Errorf("Copy() error = %v, want entry KR not found", err)
+	}
+	if _, found := container.GetEntry("all"); found {
+		t.Error("Copy() added the list with missing source lists")
+	}
+
+	if err := container.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+	if err := container.Copy("all", []string{"cn"}); err != ErrContainerFrozen {
+		t.Errorf("Copy() error = %v, want %v", err, ErrContainerFrozen)
+	}
+}
+
+func TestLookupOverlap(t *testing.T) {
+	container := must(NewContainerFromMap(map[string][]string{
+		"cn":         {"1.0.1.0/24", "1.0.2.0/23", "2001:250::/35"},
+		"au":         {"1.0.0.0/24"},
+		"cloudflare": {"1.0.0.0/24", "1.1.1.0/24"},
+		"private":    {"10.0.0.0/8"},
+	}))
+
+	tests := []struct {
+		query      string
+		searchList []string
+		want       []string
+		wantErr    bool
+	}{
+		{
+			query: "1.0.0.0/22",
+			want: []string{
+				"CN [1.0.1.0/24 1.0.2.0/23] 768 0.75",
+				"AU [1.0.0.0/24] 256 0.25",
+				"CLOUDFLARE [1.0.0.0/24] 256 0.25",
+			},
+		},
+		{
+			query:      "1.0.0.0/22",
+			searchList: []string{"au", " cn "},
+			want:       []string{"CN [1.0.1.0/24 1.0.2.0/23] 768 0.75", "AU [1.0.0.0/24] 256 0.25"},
+		},
+		{query: "1.0.1.128/25", want: []string{"CN [1.0.1.128/25] 128 1"}},
+		{query: "1.0.1.1", want: []string{"CN [1.0.1.1/32] 1 1"}},
+		{query: "10.1.0.0/8", want: []string{"PRIVATE [10.0.0.0/8] 16777216 1"}},
+		{query: "0.0.0.0/0", searchList: []string{"private"}, want: []string{"PRIVATE [10.0.0.0/8] 16777216 0.00390625"}},
+		{query: "2001:250::/32", want: []string{"CN [2001:250::/35] 9903520314283042199192993792 0.125"}},
+		{query: "::ffff:1.1.1.1", want: []string{"CLOUDFLARE [1.1.1.1/32] 1 1"}},
+		{query: "8.8.8.8/24", want: []string{}},
+		{query: "invalid", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		results, err := container.LookupOverlap(tt.query, tt.searchList...)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("LookupOverlap(%s) error = nil, want error", tt.query)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("LookupOverlap(%s) error = %v", tt.query, err)
+		}
+
+		got := make([]string, 0, len(results))
+		for _, result := range results {
+			got = append(got, fmt.Sprintf("%s %v %s %v", result.List, result.Prefixes, result.AddressCount, result.Fraction))
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("LookupOverlap(%s, %v) = %q, want %q", tt.query, tt.searchList, got, tt.want)
+		}
+	}
+}
@@ -52,3 +52,69 @@ func RegisterOutputConverter(name string, c OutputConverter) error {
 	outputConverterMap[name] = c
 	return nil
 }
+
+// ConverterInfo describes a registered converter with its actions, args
+// and an example in config file, for tooling to discover supported formats.
+type ConverterInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Actions     []Action          `json:"actions"`
+	Args        []*Arg            `json:"args"`
+	Example     *ConverterExample `json:"example"`
+}
+
+// ConverterExample is an example input or output in config file.
+type ConverterExample struct {
+	Type   string         `json:"type"`
+	Action Action         `json:"action"`
+	Args   map[string]any `json:"args,omitempty"`
+}
+
+// GetInputConverterInfos returns infos of all registered input converters sorted by name.
+func GetInputConverterInfos() []*ConverterInfo {
+	infos := make([]*ConverterInfo, 0, len(inputConverterMap))
+	for name, c := range inputConverterMap {
+		schema, _ := GetInputConverterSchema(name)
+		infos = append(infos, newConverterInfo(name, c.GetDescription(), schema, []Action{ActionAdd, ActionRemove}))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// GetOutputConverterInfos returns infos of all registered output converters sorted by name.
+func GetOutputConverterInfos() []*ConverterInfo {
+	infos := make([]*ConverterInfo, 0, len(outputConverterMap))
+	for name, c := range outputConverterMap {
+		schema, _ := GetOutputConverterSchema(name)
+		infos = append(infos, newConverterInfo(name, c.GetDescription(), schema, []Action{ActionOutput}))
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// newConverterInfo returns info of the converter, with defaultActions
+// for converters registered without schema.
+func newConverterInfo(name, description string, schema *ConverterSchema, defaultActions []Action) *ConverterInfo {
+	info := &ConverterInfo{
+		Name:        name,
+		Description: description,
+		Actions:     defaultActions,
+		Args:        []*Arg{},
+	}
+	if schema != nil {
+		info.Actions = schema.Actions
+		if schema.Args != nil {
+			info.Args = schema.Args
+		}
+	}
+
+	info.Example = &ConverterExample{
+		Type:   name,
+		Action: info.Actions[0],
+	}
+	if schema != nil {
+		info.Example.Args = schema.Example
+	}
+
+	return info
+}
@@ -5,11 +5,13 @@ import (
 	"net"
 	"net/netip"
 	"strings"
+	"sync"
 
 	"go4.org/netipx"
 )
 
 type Entry struct {
+	mu          sync.Mutex // guards building of IP sets by concurrent readers
 	name        string
 	ipv4Builder *netipx.IPSetBuilder
 	ipv6Builder *netipx.IPSetBuilder
@@ -43,6 +45,11 @@ func (e *Entry) hasIPv6Set() bool {
 	return e.ipv6Set != nil
 }
 
+func (e *Entry) resetIPSet() {
+	e.ipv4Set = nil
+	e.ipv6Set = nil
+}
+
 func (e *Entry) GetIPv4Set() (*netipx.IPSet, error) {
 	if err := e.buildIPSet(); err != nil {
 		return nil, err
@@ -253,11 +260,13 @@ func (e *Entry) add(prefix *netip.Prefix, ipType IPType) error {
 			e.ipv4Builder = new(netipx.IPSetBuilder)
 		}
 		e.ipv4Builder.AddPrefix(*prefix)
+		e.ipv4Set = nil
 	case IPv6:
 		if !e.hasIPv6Builder() {
 			e.ipv6Builder = new(netipx.IPSetBuilder)
 		}
 		e.ipv6Builder.AddPrefix(*prefix)
+		e.ipv6Set = nil
 	default:
 		return ErrInvalidIPType
 	}
@@ -270,10 +279,12 @@ func (e *Entry) remove(prefix *netip.Prefix, ipType IPType) error {
 	case IPv4:
 		if e.hasIPv4Builder() {
 			e.ipv4Builder.RemovePrefix(*prefix)
+			e.ipv4Set = nil
 		}
 	case IPv6:
 		if e.hasIPv6Builder() {
 			e.ipv6Builder.RemovePrefix(*prefix)
+			e.ipv6Set = nil
 		}
 	default:
 		return ErrInvalidIPType
@@ -305,6 +316,9 @@ func (e *Entry) RemovePrefix(cidr string) error {
 }
 
 func (e *Entry) buildIPSet() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
 	if e.hasIPv4Builder() && !e.hasIPv4Set() {
 		ipv4set, err := e.ipv4Builder.IPSet()
 		if err != nil {
@@ -360,6 +374,30 @@ func (e *Entry) MarshalPrefix(opts ...IgnoreIPOption) ([]netip.Prefix, error) {
 	return nil, fmt.Errorf("entry %s has no prefix", e.GetName())
 }
 
+// GetIPSet returns the set of both IPv4 and IPv6 addresses of the entry.
+func (e *Entry) GetIPSet(opts ...IgnoreIPOption) (*netipx.IPSet, error) {
+	var ignoreIPType IPType
+	for _, opt := range opts {
+		if opt != nil {
+			ignoreIPType = opt()
+		}
+	}
+
+	if err := e.buildIPSet(); err != nil {
+		return nil, err
+	}
+
+	var builder netipx.IPSetBuilder
+	if ignoreIPType != IPv4 && e.hasIPv4Set() {
+		builder.AddSet(e.ipv4Set)
+	}
+	if ignoreIPType != IPv6 && e.hasIPv6Set() {
+		builder.AddSet(e.ipv6Set)
+	}
+
+	return builder.IPSet()
+}
+
 func (e *Entry) MarshalIPRange(opts ...IgnoreIPOption) ([]netipx.IPRange, error) {
 	var ignoreIPType IPType
 	for _, opt := range opts {
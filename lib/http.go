@@ -0,0 +1,384 @@
+package lib
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultRetryBackoff = time.Second
+
+var (
+	cacheDir   string
+	offline    bool
+	httpConfig = new(HTTPConfig)
+
+	// Transports are shared by proxy URL to reuse connections
+	transports sync.Map
+)
+
+// HTTPConfig configures how remote files are fetched.
+// Zero values mean the defaults of the Go HTTP client.
+// Retry is a pointer, so that retrying can be disabled by an override config.
+type HTTPConfig struct {
+	Timeout      Duration          `json:"timeout,omitempty"`
+	Retry        *int              `json:"retry,omitempty"`
+	RetryBackoff Duration          `json:"retryBackoff,omitempty"`
+	Proxy        string            `json:"proxy,omitempty"`
+	UserAgent    string            `json:"userAgent,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	BearerToken  string            `json:"bearerToken,omitempty"`
+	BasicAuth    *BasicAuth        `json:"basicAuth,omitempty"`
+}
+
+type BasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Merge returns a new HTTPConfig with fields set in override taking precedence.
+// Headers of both configs are combined.
+func (h *HTTPConfig) Merge(override *HTTPConfig) *HTTPConfig {
+	merged := new(HTTPConfig)
+	if h != nil {
+		*merged = *h
+	}
+	if override == nil {
+		return merged
+	}
+
+	if override.Timeout > 0 {
+		merged.Timeout = override.Timeout
+	}
+	if override.Retry != nil {
+		merged.Retry = override.Retry
+	}
+	if override.RetryBackoff > 0 {
+		merged.RetryBackoff = override.RetryBackoff
+	}
+	if override.Proxy != "" {
+		merged.Proxy = override.Proxy
+	}
+	if override.UserAgent != "" {
+		merged.UserAgent = override.UserAgent
+	}
+	if override.BearerToken != "" {
+		merged.BearerToken = override.BearerToken
+	}
+	if override.BasicAuth != nil {
+		merged.BasicAuth = override.BasicAuth
+	}
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string, len(merged.Headers)+len(override.Headers))
+		for k, v := range merged.Headers {
+			headers[k] = v
+		}
+		for k, v := range override.Headers {
+			headers[k] = v
+		}
+		merged.Headers = headers
+	}
+
+	return merged
+}
+
+func (h *HTTPConfig) client() (*http.Client, error) {
+	client := &http.Client{Timeout: time.Duration(h.Timeout)}
+	if h.Proxy == "" {
+		return client, nil
+	}
+
+	if transport, found := transports.Load(h.Proxy); found {
+		client.Transport = transport.(*http.Transport)
+		return client, nil
+	}
+
+	proxyURL, err := url.Parse(h.Proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %s: %v", h.Proxy, err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	actual, _ := transports.LoadOrStore(h.Proxy, transport)
+	client.Transport = actual.(*http.Transport)
+	return client, nil
+}
+
+func (h *HTTPConfig) setHeaders(req *http.Request) {
+	if h.UserAgent != "" {
+		req.Header.Set("User-Agent", h.UserAgent)
+	}
+	for k, v := range h.Headers {
+		req.Header.Set(k, v)
+	}
+	switch {
+	case h.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+h.BearerToken)
+	case h.BasicAuth != nil:
+		req.SetBasicAuth(h.BasicAuth.Username, h.BasicAuth.Password)
+	}
+}
+
+// do sends req, retrying on network errors and server errors
+// with exponential backoff.
+func (h *HTTPConfig) do(req *http.Request) (*http.Response, error) {
+	client, err := h.client()
+	if err != nil {
+		return nil, err
+	}
+	h.setHeaders(req)
+
+	backoff := time.Duration(h.RetryBackoff)
+	if backoff <= 0 {
+		backoff = defaultRetryBackoff
+	}
+	retry := 0
+	if h.Retry != nil {
+		retry = *h.Retry
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := client.Do(req)
+		retryable := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		if !retryable || attempt >= retry {
+			return resp, err
+		}
+
+		if err != nil {
+			log.Printf("⚠️ failed to get remote content -> %s: %v, retrying in %s\n", req.URL.Redacted(), err, backoff)
+		} else {
+			resp.Body.Close()
+			log.Printf("⚠️ failed to get remote content -> %s: %s, retrying in %s\n", req.URL.Redacted(), resp.Status, backoff)
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// SetHTTPConfig sets the global config used to fetch all remote files.
+// Per-input configs are merged into it.
+func SetHTTPConfig(config *HTTPConfig) {
+	httpConfig = new(HTTPConfig).Merge(config)
+}
+
+// GetHTTPConfig returns the global config used to fetch all remote files.
+func GetHTTPConfig() *HTTPConfig {
+	return httpConfig
+}
+
+// Duration is a time.Duration that can be unmarshaled from
+// a duration string like "30s" or a number of seconds.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err != nil {
+		var seconds float64
+		if err := json.Unmarshal(data, &seconds); err != nil {
+			return fmt.Errorf("invalid duration %s", data)
+		}
+		*d = Duration(seconds * float64(time.Second))
+		return nil
+	}
+
+	str = strings.TrimSpace(str)
+	if str == "" {
+		*d = 0
+		return nil
+	}
+
+	duration, err := time.ParseDuration(str)
+	if err != nil {
+		return err
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// SetCacheDir sets the directory to cache remote files in,
+// so that unchanged files will not be downloaded again.
+// An empty dir disables caching.
+func SetCacheDir(dir string) {
+	cacheDir = dir
+}
+
+// SetOffline makes remote files be served from the cache directory only,
+// without any network access.
+func SetOffline(enabled bool) {
+	offline = enabled
+}
+
+// cacheMeta is saved along with the cached file. Key is the hash of the URL,
+// and URL has credentials in it, like license keys in query, hidden.
+type cacheMeta struct {
+	Key          string `json:"key"`
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+type cacheItem struct {
+	key      string
+	bodyPath string
+	metaPath string
+}
+
+func newCacheItem(url string) *cacheItem {
+	sum := sha256.Sum256([]byte(url))
+	key := hex.EncodeToString(sum[:])
+	return &cacheItem{
+		key:      key,
+		bodyPath: filepath.Join(cacheDir, key),
+		metaPath: filepath.Join(cacheDir, key+".json"),
+	}
+}
+
+func (c *cacheItem) load() (*cacheMeta, bool) {
+	data, err := os.ReadFile(c.metaPath)
+	if err != nil {
+		return nil, false
+	}
+
+	meta := new(cacheMeta)
+	if err := json.Unmarshal(data, meta); err != nil || meta.Key != c.key {
+		return nil, false
+	}
+
+	if _, err := os.Stat(c.bodyPath); err != nil {
+		return nil, false
+	}
+
+	return meta, true
+}
+
+func (c *cacheItem) save(body io.Reader, meta *cacheMeta) error {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return err
+	}
+
+	// Write to temporary files first, so that concurrent readers
+	// never see partially written files.
+	if err := writeFileAtomic(c.bodyPath, body); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomic(c.metaPath, bytes.NewReader(data))
+}
+
+func writeFileAtomic(path string, r io.Reader) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
+
+// fetchRemoteURL gets the content of url, using the cache directory if set.
+// Conditional requests with ETag and Last-Modified are sent for cached files.
+// The override config, if any, is merged into the global HTTP config.
+func fetchRemoteURL(url string, override ...*HTTPConfig) (io.ReadCloser, error) {
+	config := httpConfig
+	for _, o := range override {
+		config = config.Merge(o)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheDir == "" {
+		if offline {
+			return nil, fmt.Errorf("failed to get remote content -> %s: offline mode requires a cache directory", url)
+		}
+
+		resp, err := config.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to get remote content -> %s: %s", url, resp.Status)
+		}
+
+		return resp.Body, nil
+	}
+
+	item := newCacheItem(url)
+	meta, cached := item.load()
+
+	if offline {
+		if !cached {
+			return nil, fmt.Errorf("failed to get remote content -> %s: not found in cache directory %s in offline mode", url, cacheDir)
+		}
+		return os.Open(item.bodyPath)
+	}
+
+	if cached {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+
+	resp, err := config.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		return os.Open(item.bodyPath)
+
+	case resp.StatusCode == http.StatusOK:
+		err := item.save(resp.Body, &cacheMeta{
+			Key:          item.key,
+			URL:          redactURL(url),
+			ETag:         resp.Header.Get("ETag"),
+			LastModified: resp.Header.Get("Last-Modified"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return os.Open(item.bodyPath)
+
+	default:
+		return nil, fmt.Errorf("failed to get remote content -> %s: %s", url, resp.Status)
+	}
+}
@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func setTestCache(t *testing.T, dir string, enabled bool) {
+	t.Helper()
+	SetCacheDir(dir)
+	SetOffline(enabled)
+	t.Cleanup(func() {
+		SetCacheDir("")
+		SetOffline(false)
+	})
+}
+
+func readRemoteURL(t *testing.T, url string) (string, error) {
+	t.Helper()
+	rc, err := fetchRemoteURL(url)
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	content, err := io.ReadAll(rc)
+	return string(content), err
+}
+
+func TestFetchRemoteURLCache(t *testing.T) {
+	var requests, notModified atomic.Int32
+	body := "1.0.0.0/24\n"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		io.WriteString(w, body)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	setTestCache(t, dir, false)
+	url := server.URL + "/list.txt?license_key=secret"
+
+	// Cached on the first request, and revalidated with ETag later
+	for range 2 {
+		content, err := readRemoteURL(t, url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if content != body {
+			t.Errorf("content = %q, want %q", content, body)
+		}
+	}
+	if requests.Load() != 2 || notModified.Load() != 1 {
+		t.Errorf("requests = %d, not modified = %d, want 2 and 1", requests.Load(), notModified.Load())
+	}
+
+	// Credentials in URL are not saved
+	item := newCacheItem(url)
+	data, err := os.ReadFile(item.metaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") {
+		t.Errorf("cache meta = %s, want credentials hidden", data)
+	}
+	var meta cacheMeta
+	if err := json.Unmarshal(data, &meta); err != nil || meta.Key != item.key || meta.ETag != `"v1"` {
+		t.Errorf("cache meta = %+v, error = %v", meta, err)
+	}
+
+	// Served from cache without network access in offline mode
+	setTestCache(t, dir, true)
+	if content, err := readRemoteURL(t, url); err != nil || content != body {
+		t.Errorf("offline content = %q, error = %v, want %q", content, err, body)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("requests = %d in offline mode, want none", requests.Load()-2)
+	}
+	if _, err := readRemoteURL(t, server.URL+"/other.txt"); err == nil || !strings.Contains(err.Error(), "not found in cache directory") {
+		t.Errorf("uncached file error = %v, want not found in cache directory", err)
+	}
+
+	setTestCache(t, "", true)
+	if _, err := readRemoteURL(t, url); err == nil || !strings.Contains(err.Error(), "requires a cache directory") {
+		t.Errorf("offline without cache error = %v, want requires a cache directory", err)
+	}
+}
+
+func TestFetchRemoteURLCacheRefetched(t *testing.T) {
+	var version atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", fmt.Sprintf(`"v%d"`, version.Load()))
+		fmt.Fprintf(w, "version %d", version.Load())
+	}))
+	defer server.Close()
+
+	setTestCache(t, t.TempDir(), false)
+	url := server.URL + "/list.txt"
+
+	// Files changed remotely are fetched again
+	for v := range int32(2) {
+		version.Store(v)
+		want := fmt.Sprintf("version %d", v)
+		if content, err := readRemoteURL(t, url); err != nil || content != want {
+			t.Errorf("content = %q, error = %v, want %q", content, err, want)
+		}
+	}
+
+	// Cache saved for other URLs is not used
+	if err := os.WriteFile(newCacheItem(url).metaPath, []byte(`{"key": "other"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, found := newCacheItem(url).load(); found {
+		t.Error("load() found cache with mismatched key")
+	}
+}
+
+func TestHTTPConfigMerge(t *testing.T) {
+	zero, three := 0, 3
+	base := &HTTPConfig{Retry: &three, Proxy: "http://127.0.0.1:8080", Headers: map[string]string{"A": "1", "B": "1"}}
+
+	merged := base.Merge(&HTTPConfig{Retry: &zero, Headers: map[string]string{"B": "2"}})
+	if merged.Retry == nil || *merged.Retry != 0 {
+		t.Errorf("merged retry = %v, want 0", merged.Retry)
+	}
+	if merged.Proxy != base.Proxy || merged.Headers["A"] != "1" || merged.Headers["B"] != "2" {
+		t.Errorf("merged = %+v", merged)
+	}
+
+	if merged := base.Merge(new(HTTPConfig)); merged.Retry == nil || *merged.Retry != 3 {
+		t.Errorf("merged retry = %v, want 3 kept when unset", merged.Retry)
+	}
+	if base.Headers["B"] != "1" {
+		t.Error("Merge() modified the base config")
+	}
+
+	var config HTTPConfig
+	if err := json.Unmarshal([]byte(`{"retry": 0}`), &config); err != nil || config.Retry == nil || *config.Retry != 0 {
+		t.Errorf("unmarshaled retry = %v, error = %v, want 0", config.Retry, err)
+	}
+}
+
+func TestHTTPConfigRetry(t *testing.T) {
+	intPtr := func(v int) *int { return &v }
+	backoff := 20 * time.Millisecond
+
+	tests := []struct {
+		name     string
+		retry    *int
+		statuses []int // statuses of each request, 200 after them
+		requests int
+		wantErr  string
+	}{
+		{name: "no retry by default", statuses: []int{503}, requests: 1, wantErr: "503"},
+		{name: "retry disabled", retry: intPtr(0), statuses: []int{500}, requests: 1, wantErr: "500"},
+		{name: "succeed after retries", retry: intPtr(3), statuses: []int{503, 429, 502}, requests: 4},
+		{name: "give up", retry: intPtr(2), statuses: []int{503, 503, 503, 503}, requests: 3, wantErr: "503"},
+		{name: "client errors not retried", retry: intPtr(3), statuses: []int{404}, requests: 1, wantErr: "404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			times := make([]time.Time, 0)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				times = append(times, time.Now())
+				attempt := len(times)
+				mu.Unlock()
+
+				if attempt <= len(tt.statuses) {
+					w.WriteHeader(tt.statuses[attempt-1])
+					return
+				}
+				io.WriteString(w, "ok")
+			}))
+			defer server.Close()
+
+			_, err := GetRemoteURLContent(server.URL, &HTTPConfig{Retry: tt.retry, RetryBackoff: Duration(backoff)})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("GetRemoteURLContent() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("GetRemoteURLContent() error = %v, want error containing %q", err, tt.wantErr)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if len(times) != tt.requests {
+				t.Fatalf("requests = %d, want %d", len(times), tt.requests)
+			}
+			// Backoff doubles on each retry
+			for i := 1; i < len(times); i++ {
+				if gap, want := times[i].Sub(times[i-1]), backoff<<(i-1); gap < want {
+					t.Errorf("retry #%d after %s, want at least %s", i, gap, want)
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPConfigRetryNetworkError(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	retry := 2
+	start := time.Now()
+	_, err := GetRemoteURLContent(url, &HTTPConfig{Retry: &retry, RetryBackoff: Duration(10 * time.Millisecond)})
+	if err == nil {
+		t.Fatal("GetRemoteURLContent() error = nil, want network error")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("gave up after %s, want 2 retries with backoff of 10ms and 20ms", elapsed)
+	}
+}
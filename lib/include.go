@@ -0,0 +1,269 @@
+package lib
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// rawConfig is a config file before converters are created,
+// with inputs and outputs of its included config files merged.
+type rawConfig struct {
+	Include     []string                     `json:"include"`
+	InputGroups map[string][]json.RawMessage `json:"inputGroups"`
+	Input       []json.RawMessage            `json:"input"`
+	Output      []json.RawMessage            `json:"output"`
+	Concurrency int                          `json:"concurrency"`
+	HTTP        *HTTPConfig                  `json:"http"`
+
+	// Positions of inputs, outputs and inputs of input groups in config files,
+	// in the form of "file:line", or empty if unknown
+	inputPos  []string
+	outputPos []string
+	groupPos  map[string][]string
+}
+
+func isRemoteURI(uri string) bool {
+	uri = strings.ToLower(uri)
+	return strings.HasPrefix(uri, "http://") || strings.HasPrefix(uri, "https://")
+}
+
+func readConfigFile(uri string) ([]byte, error) {
+	if isRemoteURI(uri) {
+		return GetRemoteURLContent(uri)
+	}
+	return os.ReadFile(uri)
+}
+
+// loadRawConfig reads the config file and the config files it includes.
+// stack holds the config files including it, to detect include cycles,
+// and included holds all config files already read, which are included
+// only once, like the shared file of includes in a diamond shape.
+func loadRawConfig(uri string, stack []string, included map[string]bool) (*rawConfig, error) {
+	key := uri
+	if !isRemoteURI(uri) {
+		if abs, err := filepath.Abs(uri); err == nil {
+			key = abs
+		}
+	}
+	if slices.Contains(stack, key) {
+		return nil, fmt.Errorf("include cycle: %s", strings.Join(append(slices.Clone(stack), key), " -> "))
+	}
+	if included[key] {
+		return new(rawConfig), nil
+	}
+	included[key] = true
+
+	content, err := readConfigFile(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRawConfig(content, uri, append(slices.Clone(stack), key), included)
+}
+
+// parseRawConfig parses content of the config file at uri, which is empty if unknown,
+// and merges the config files it includes before its own inputs and outputs.
+func parseRawConfig(content []byte, uri string, stack []string, included map[string]bool) (*rawConfig, error) {
+	raw := new(rawConfig)
+	original := content
+	content, err := convertConfigToJSON(uri, content)
+	if err == nil {
+		content, err = expandConfigTemplate(content)
+	}
+	if err == nil && strict {
+		err = raw.checkFields(content)
+	}
+	if err == nil {
+		err = json.Unmarshal(content, raw)
+	}
+	if err != nil {
+		if uri != "" {
+			return nil, fmt.Errorf("%s: %w", uri, err)
+		}
+		return nil, err
+	}
+	raw.locate(uri, locateConfigElements(uri, original))
+
+	merged := &rawConfig{
+		InputGroups: make(map[string][]json.RawMessage),
+		groupPos:    make(map[string][]string),
+	}
+	for _, include := range raw.Include {
+		include = strings.TrimSpace(include)
+		if include == "" {
+			continue
+		}
+
+		includeURI, err := resolveIncludeURI(uri, include)
+		if err != nil {
+			return nil, err
+		}
+
+		included, err := loadRawConfig(includeURI, stack, included)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", include, err)
+		}
+		merged.merge(included)
+	}
+	merged.merge(raw)
+
+	return merged, nil
+}
+
+// locate sets positions of inputs, outputs and inputs of input groups
+// from their lines in the config file at uri.
+func (c *rawConfig) locate(uri string, lines map[string][]int) {
+	positions := func(key string, count int) []string {
+		result := make([]string, count)
+		// Lines may be missing or mismatched if elements are not located correctly
+		if len(lines[key]) != count {
+			return result
+		}
+		for idx, line := range lines[key] {
+			if uri != "" {
+				result[idx] = fmt.Sprintf("%s:%d", uri, line)
+			} else {
+				result[idx] = fmt.Sprintf("line %d", line)
+			}
+		}
+		return result
+	}
+
+	c.inputPos = positions(configKeyInput, len(c.Input))
+	c.outputPos = positions(configKeyOutput, len(c.Output))
+	c.groupPos = make(map[string][]string, len(c.InputGroups))
+	for name, group := range c.InputGroups {
+		c.groupPos[name] = positions(configKeyInputGroups+"."+name, len(group))
+	}
+}
+
+// checkFields returns an error if the config file in data has unknown fields,
+// except for $schema used by editors.
+func (c *rawConfig) checkFields(data []byte) error {
+	if err := checkFields(data, "field", "$schema", "include", "inputGroups", "input", "output", "concurrency", "http"); err != nil {
+		return err
+	}
+
+	var temp struct {
+		HTTP json.RawMessage `json:"http"`
+	}
+	if err := json.Unmarshal(data, &temp); err != nil {
+		return err
+	}
+	return checkHTTPConfigFields(temp.HTTP)
+}
+
+// resolveIncludeURI resolves the path of an included config file
+// relative to the config file including it.
+func resolveIncludeURI(base, include string) (string, error) {
+	switch {
+	case isRemoteURI(include):
+		return include, nil
+
+	case isRemoteURI(base):
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", err
+		}
+		includeURL, err := url.Parse(include)
+		if err != nil {
+			return "", err
+		}
+		return baseURL.ResolveReference(includeURL).String(), nil
+
+	case base == "", filepath.IsAbs(include):
+		return include, nil
+
+	default:
+		return filepath.Join(filepath.Dir(base), include), nil
+	}
+}
+
+// merge appends inputs and outputs of other to c. Input groups,
+// concurrency and HTTP settings of other take precedence.
+func (c *rawConfig) merge(other *rawConfig) {
+	c.Input = append(c.Input, other.Input...)
+	c.Output = append(c.Output, other.Output...)
+	c.inputPos = append(c.inputPos, padPositions(other.inputPos, len(other.Input))...)
+	c.outputPos = append(c.outputPos, padPositions(other.outputPos, len(other.Output))...)
+
+	for name, group := range other.InputGroups {
+		c.InputGroups[name] = group
+		c.groupPos[name] = padPositions(other.groupPos[name], len(group))
+	}
+
+	if other.Concurrency > 0 {
+		c.Concurrency = other.Concurrency
+	}
+
+	if other.HTTP != nil {
+		c.HTTP = c.HTTP.Merge(other.HTTP)
+	}
+}
+
+// padPositions returns positions with count elements, where unknown ones are empty.
+func padPositions(positions []string, count int) []string {
+	if len(positions) == count {
+		return positions
+	}
+	return make([]string, count)
+}
+
+// withPosition prefixes err with the position of the element causing it, if known.
+func withPosition(pos string, err error) error {
+	if pos == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", pos, err)
+}
+
+// flatten returns the config in JSON format, with input groups
+// referenced by {"group": "name"} in inputs replaced by their inputs,
+// and positions of the inputs after replacement.
+func (c *rawConfig) flatten() ([]byte, []string, error) {
+	inputPos := padPositions(c.inputPos, len(c.Input))
+	inputs := make([]json.RawMessage, 0, len(c.Input))
+	positions := make([]string, 0, len(c.Input))
+	for idx, input := range c.Input {
+		var ref struct {
+			Group string `json:"group"`
+		}
+		json.Unmarshal(input, &ref)
+
+		if ref.Group == "" {
+			inputs = append(inputs, input)
+			positions = append(positions, inputPos[idx])
+			continue
+		}
+		if strict {
+			if err := checkFields(input, "field", "group"); err != nil {
+				return nil, nil, withPosition(inputPos[idx], fmt.Errorf("reference to input group %s: %w", ref.Group, err))
+			}
+		}
+
+		group, found := c.InputGroups[ref.Group]
+		if !found {
+			return nil, nil, withPosition(inputPos[idx], fmt.Errorf("input group %s not found", ref.Group))
+		}
+		inputs = append(inputs, group...)
+		positions = append(positions, padPositions(c.groupPos[ref.Group], len(group))...)
+	}
+
+	content, err := json.Marshal(&struct {
+		Input       []json.RawMessage `json:"input"`
+		Output      []json.RawMessage `json:"output"`
+		Concurrency int               `json:"concurrency,omitempty"`
+		HTTP        *HTTPConfig       `json:"http,omitempty"`
+	}{
+		Input:       inputs,
+		Output:      c.Output,
+		Concurrency: c.Concurrency,
+		HTTP:        c.HTTP,
+	})
+	return content, positions, err
+}
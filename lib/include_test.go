@@ -0,0 +1,93 @@
+package lib
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfigFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func inputTypes(t *testing.T, raw *rawConfig) []string {
+	t.Helper()
+	types := make([]string, 0, len(raw.Input))
+	for _, input := range raw.Input {
+		var tmp struct {
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal(input, &tmp); err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, tmp.Type)
+	}
+	return types
+}
+
+func TestLoadRawConfigIncludeCycle(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "self",
+			files: map[string]string{
+				"a.json": `{"include": ["a.json"]}`,
+			},
+			wantErr: "include cycle",
+		},
+		{
+			name: "indirect",
+			files: map[string]string{
+				"a.json":     `{"include": ["b.json"]}`,
+				"b.json":     `{"include": ["sub/c.json"]}`,
+				"sub/c.json": `{"include": ["../a.json"]}`,
+			},
+			wantErr: "include cycle",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeConfigFiles(t, tt.files)
+			_, err := loadRawConfig(filepath.Join(dir, "a.json"), nil, make(map[string]bool))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("loadRawConfig() error = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadRawConfigIncludeDiamond(t *testing.T) {
+	dir := writeConfigFiles(t, map[string]string{
+		"a.json": `{"include": ["b.json", "c.json"], "input": [{"type": "a"}]}`,
+		"b.json": `{"include": ["d.json"], "input": [{"type": "b"}]}`,
+		"c.json": `{"include": ["./d.json"], "input": [{"type": "c"}]}`,
+		"d.json": `{"input": [{"type": "d"}]}`,
+	})
+
+	raw, err := loadRawConfig(filepath.Join(dir, "a.json"), nil, make(map[string]bool))
+	if err != nil {
+		t.Fatalf("loadRawConfig() error = %v", err)
+	}
+
+	got := strings.Join(inputTypes(t, raw), ",")
+	if want := "d,b,c,a"; got != want {
+		t.Errorf("inputs = %s, want %s", got, want)
+	}
+}
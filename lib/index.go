@@ -0,0 +1,203 @@
+package lib
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+
+	"go4.org/netipx"
+)
+
+// lookupIndex is a compiled index of a frozen container to lookup IPs and CIDRs
+// in all lists in O(log n) time. The address space is split into segments,
+// each of which is covered by the same lists. A segment starts at an address
+// in starts and ends before the next one, and the lists covering it are
+// the bits set in sets[setIndexes[i]]. Identical bitsets are shared.
+type lookupIndex struct {
+	names []string
+	ipv4  *segmentTable
+	ipv6  *segmentTable
+}
+
+type segmentTable struct {
+	starts     []netip.Addr
+	setIndexes []uint32
+	sets       []bitset
+}
+
+type bitset []uint64
+
+func newBitset(size int) bitset {
+	return make(bitset, (size+63)/64)
+}
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << (i % 64)
+}
+
+func (b bitset) unset(i int) {
+	b[i/64] &^= 1 << (i % 64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b bitset) key() string {
+	var sb strings.Builder
+	for _, word := range b {
+		for i := 0; i < 8; i++ {
+			sb.WriteByte(byte(word >> (i * 8)))
+		}
+	}
+	return sb.String()
+}
+
+type indexEvent struct {
+	addr  netip.Addr
+	list  int
+	start bool
+}
+
+func newLookupIndex(entries map[string]*Entry) (*lookupIndex, error) {
+	index := &lookupIndex{
+		names: make([]string, 0, len(entries)),
+	}
+	for name := range entries {
+		index.names = append(index.names, name)
+	}
+	slices.Sort(index.names)
+
+	ipv4Events := make([]indexEvent, 0, 1024)
+	ipv6Events := make([]indexEvent, 0, 1024)
+	for i, name := range index.names {
+		entry := entries[name]
+		if err := entry.buildIPSet(); err != nil {
+			return nil, err
+		}
+
+		if entry.hasIPv4Set() {
+			ipv4Events = appendIndexEvents(ipv4Events, i, entry.ipv4Set.Ranges())
+		}
+		if entry.hasIPv6Set() {
+			ipv6Events = appendIndexEvents(ipv6Events, i, entry.ipv6Set.Ranges())
+		}
+	}
+
+	index.ipv4 = newSegmentTable(ipv4Events, len(index.names))
+	index.ipv6 = newSegmentTable(ipv6Events, len(index.names))
+
+	return index, nil
+}
+
+func appendIndexEvents(events []indexEvent, list int, ranges []netipx.IPRange) []indexEvent {
+	for _, r := range ranges {
+		events = append(events, indexEvent{addr: r.From(), list: list, start: true})
+		// The range ending at the last address of the address space has no end event
+		if next := r.To().Next(); next.IsValid() {
+			events = append(events, indexEvent{addr: next, list: list})
+		}
+	}
+	return events
+}
+
+func newSegmentTable(events []indexEvent, size int) *segmentTable {
+	slices.SortFunc(events, func(a, b indexEvent) int {
+		return a.addr.Compare(b.addr)
+	})
+
+	table := new(segmentTable)
+	setIndexMap := make(map[string]uint32)
+	current := newBitset(size)
+
+	for i := 0; i < len(events); {
+		addr := events[i].addr
+		for ; i < len(events) && events[i].addr == addr; i++ {
+			// Ranges of the same list never overlap or adjoin,
+			// so events at the same address come from different lists.
+			if events[i].start {
+				current.set(events[i].list)
+			} else {
+				current.unset(events[i].list)
+			}
+		}
+
+		key := current.key()
+		setIndex, found := setIndexMap[key]
+		if !found {
+			setIndex = uint32(len(table.sets))
+			setIndexMap[key] = setIndex
+			table.sets = append(table.sets, slices.Clone(current))
+		}
+
+		// Merge with the previous segment covered by the same lists
+		if n := len(table.setIndexes); n > 0 && table.setIndexes[n-1] == setIndex {
+			continue
+		}
+
+		table.starts = append(table.starts, addr)
+		table.setIndexes = append(table.setIndexes, setIndex)
+	}
+
+	return table
+}
+
+// segmentOf returns the index of the segment containing addr, or -1 if not found.
+func (t *segmentTable) segmentOf(addr netip.Addr) int {
+	i, found := slices.BinarySearchFunc(t.starts, addr, func(start, target netip.Addr) int {
+		return start.Compare(target)
+	})
+	if found {
+		return i
+	}
+	return i - 1
+}
+
+// lookup returns the bitset of lists containing all addresses from first to last.
+func (t *segmentTable) lookup(first, last netip.Addr, size int) bitset {
+	result := newBitset(size)
+
+	start := t.segmentOf(first)
+	if start < 0 {
+		return result
+	}
+	copy(result, t.sets[t.setIndexes[start]])
+
+	for i := start + 1; i < len(t.starts) && t.starts[i].Compare(last) <= 0; i++ {
+		set := t.sets[t.setIndexes[i]]
+		for j := range result {
+			result[j] &= set[j]
+		}
+	}
+
+	return result
+}
+
+// lookup returns the names of lists containing addrOrPrefix
+// among lists in searchMap, or all lists if searchMap is empty.
+func (idx *lookupIndex) lookup(addrOrPrefix any, iptype IPType, searchMap map[string]bool) []string {
+	table := idx.ipv4
+	if iptype == IPv6 {
+		table = idx.ipv6
+	}
+
+	var first, last netip.Addr
+	switch addrOrPrefix := addrOrPrefix.(type) {
+	case netip.Prefix:
+		first = addrOrPrefix.Masked().Addr()
+		last = netipx.PrefixLastIP(addrOrPrefix)
+	case netip.Addr:
+		first, last = addrOrPrefix, addrOrPrefix
+	}
+
+	set := table.lookup(first, last, len(idx.names))
+
+	result := make([]string, 0, 8)
+	for i, name := range idx.names {
+		if set.has(i) && (len(searchMap) == 0 || searchMap[name]) {
+			result = append(result, name)
+		}
+	}
+
+	return result
+}
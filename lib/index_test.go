@@ -0,0 +1,134 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/netip"
+	"slices"
+	"testing"
+
+	"go4.org/netipx"
+)
+
+func randomPrefix(r *rand.Rand, base netip.Prefix, minBits, maxBits int) netip.Prefix {
+	bytes := base.Addr().AsSlice()
+	for i := base.Bits() / 8; i < len(bytes); i++ {
+		bytes[i] = byte(r.IntN(256))
+	}
+	addr, _ := netip.AddrFromSlice(bytes)
+	return netip.PrefixFrom(addr, minBits+r.IntN(maxBits-minBits+1)).Masked()
+}
+
+func newTestContainers(t *testing.T, lists map[string][]netip.Prefix) (linear, frozen Container) {
+	t.Helper()
+	linear = must(NewContainerFromMap(lists))
+	frozen = must(NewContainerFromMap(lists))
+	if err := frozen.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+	return linear, frozen
+}
+
+func TestLookupIndexMatchesLinearScan(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	ipv4Base := netip.MustParsePrefix("10.0.0.0/16")
+	ipv6Base := netip.MustParsePrefix("2001:db8::/32")
+
+	lists := map[string][]netip.Prefix{
+		// Lists covering the first and last addresses of the address space
+		"ALL":  {netip.MustParsePrefix("0.0.0.0/0"), netip.MustParsePrefix("::/0")},
+		"LAST": {netip.MustParsePrefix("255.255.255.0/24"), netip.MustParsePrefix("ffff::/16")},
+		"ZERO": {netip.MustParsePrefix("0.0.0.0/8")},
+		// A range ending right before the last address of 10.1.0.0/28
+		"EDGE": {
+			netip.MustParsePrefix("10.1.0.0/29"),
+			netip.MustParsePrefix("10.1.0.8/30"),
+			netip.MustParsePrefix("10.1.0.12/31"),
+			netip.MustParsePrefix("10.1.0.14/32"),
+		},
+	}
+	for i := 0; i < 20; i++ {
+		prefixes := make([]netip.Prefix, 0)
+		for j := 0; j < 30; j++ {
+			prefixes = append(prefixes, randomPrefix(r, ipv4Base, 18, 28))
+			// Small prefixes make segments start and end at any address
+			prefixes = append(prefixes, randomPrefix(r, ipv4Base, 29, 32))
+		}
+		// Some lists have only IPv4 addresses
+		if i%3 != 0 {
+			for j := 0; j < 30; j++ {
+				prefixes = append(prefixes, randomPrefix(r, ipv6Base, 36, 56))
+			}
+		}
+		lists[fmt.Sprintf("LIST%d", i)] = prefixes
+	}
+
+	linear, frozen := newTestContainers(t, lists)
+
+	queries := []string{"0.0.0.0", "255.255.255.255", "255.255.255.0/24", "::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::ffff:10.0.0.1", "0.0.0.0/0", "10.1.0.0/28"}
+	// IPv4-mapped IPv6 CIDRs are looked up as IPv4 CIDRs
+	queries = append(queries, "::ffff:1.2.3.0/120", "::ffff:255.255.255.0/120", "::ffff:10.1.0.0/124", "::ffff:0.0.0.0/96")
+	for _, prefixes := range lists {
+		for _, prefix := range prefixes {
+			first, last := prefix.Addr(), netipx.PrefixLastIP(prefix)
+			queries = append(queries, prefix.String(), first.String(), last.String())
+			if prev := first.Prev(); prev.IsValid() {
+				queries = append(queries, prev.String())
+			}
+			if next := last.Next(); next.IsValid() {
+				queries = append(queries, next.String())
+			}
+		}
+	}
+	for i := 0; i < 2000; i++ {
+		queries = append(queries,
+			randomPrefix(r, ipv4Base, 32, 32).Addr().String(),
+			randomPrefix(r, ipv4Base, 16, 32).String(),
+			randomPrefix(r, ipv6Base, 128, 128).Addr().String(),
+			randomPrefix(r, ipv6Base, 32, 128).String(),
+		)
+	}
+
+	searchLists := [][]string{nil, {"list1", "LIST2", "all"}, {"nope"}}
+	for _, query := range queries {
+		for _, searchList := range searchLists {
+			want, wantFound, err := linear.Lookup(query, searchList...)
+			if err != nil {
+				t.Fatalf("linear Lookup(%s) error = %v", query, err)
+			}
+			got, gotFound, err := frozen.Lookup(query, searchList...)
+			if err != nil {
+				t.Fatalf("frozen Lookup(%s) error = %v", query, err)
+			}
+
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(got, want) || gotFound != wantFound {
+				t.Fatalf("Lookup(%s, %v) = %v, %v with index, want %v, %v", query, searchList, got, gotFound, want, wantFound)
+			}
+		}
+	}
+}
+
+func TestFrozenContainerCannotBeModified(t *testing.T) {
+	container := NewContainer()
+	entry := NewEntry("cn")
+	if err := entry.AddPrefix("1.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if err := container.Add(entry); err != nil {
+		t.Fatal(err)
+	}
+	if err := container.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+
+	other := NewEntry("us")
+	if err := other.AddPrefix("2.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if err := container.Add(other); !errors.Is(err, ErrContainerFrozen) {
+		t.Errorf("Add() to frozen container error = %v, want %v", err, ErrContainerFrozen)
+	}
+}
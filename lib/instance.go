@@ -3,10 +3,8 @@ package lib
 import (
 	"encoding/json"
 	"errors"
-	"os"
+	"path/filepath"
 	"strings"
-
-	"github.com/tailscale/hujson"
 )
 
 type Instance interface {
@@ -19,52 +17,73 @@ type Instance interface {
 	RunInput(Container) error
 	RunOutput(Container) error
 	Run() error
+	Plan() *Plan
 }
 
 type instance struct {
 	input  []InputConverter
 	output []OutputConverter
+
+	// Args in config file of converters, for plan
+	args map[any]json.RawMessage
 }
 
 func NewInstance() (Instance, error) {
 	return &instance{
 		input:  make([]InputConverter, 0),
 		output: make([]OutputConverter, 0),
+		args:   make(map[any]json.RawMessage),
 	}, nil
 }
 
 func (i *instance) InitConfig(configFile string) error {
-	var content []byte
-	var err error
-	configFile = strings.TrimSpace(configFile)
-	if strings.HasPrefix(strings.ToLower(configFile), "http://") || strings.HasPrefix(strings.ToLower(configFile), "https://") {
-		content, err = GetRemoteURLContent(configFile)
-	} else {
-		content, err = os.ReadFile(configFile)
-	}
+	raw, err := loadRawConfig(strings.TrimSpace(configFile), nil, make(map[string]bool))
 	if err != nil {
 		return err
 	}
 
-	return i.InitConfigFromBytes(content)
+	return i.initConfigFromRaw(raw)
 }
 
+// InitConfigFromBytes initializes the instance from content of a config file,
+// whose included config files are relative to the working directory.
 func (i *instance) InitConfigFromBytes(content []byte) error {
-	config := new(config)
+	raw, err := parseRawConfig(content, "", nil, make(map[string]bool))
+	if err != nil {
+		return err
+	}
+
+	return i.initConfigFromRaw(raw)
+}
 
-	// Support JSON with comments and trailing commas
-	content, _ = hujson.Standardize(content)
+func (i *instance) initConfigFromRaw(raw *rawConfig) error {
+	content, inputPos, err := raw.flatten()
+	if err != nil {
+		return err
+	}
 
+	config := &config{inputPos: inputPos, outputPos: padPositions(raw.outputPos, len(raw.Output))}
 	if err := json.Unmarshal(content, &config); err != nil {
 		return err
 	}
 
+	if config.Concurrency > 0 {
+		SetConcurrency(config.Concurrency)
+	}
+
+	// Settings already made globally, e.g. by command line flags, take precedence
+	if config.HTTP != nil {
+		SetHTTPConfig(config.HTTP.Merge(httpConfig))
+	}
+
 	for _, input := range config.Input {
 		i.input = append(i.input, input.converter)
+		i.args[input.converter] = input.args
 	}
 
 	for _, output := range config.Output {
 		i.output = append(i.output, output.converter)
+		i.args[output.converter] = output.args
 	}
 
 	return nil
@@ -87,9 +106,49 @@ func (i *instance) ResetOutput() {
 }
 
 func (i *instance) RunInput(container Container) error {
-	var err error
-	for _, ic := range i.input {
-		container, err = ic.Input(container)
+	// Prefetch concurrently in config order, while the container is
+	// still modified sequentially in config order to keep results deterministic.
+	prefetched := make([]chan error, len(i.input))
+	for idx, ic := range i.input {
+		if _, ok := ic.(Prefetcher); ok {
+			prefetched[idx] = make(chan error, 1)
+		}
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	// A slot is held until the prefetched data is consumed by Input,
+	// so that at most as many inputs as the concurrency are kept in memory.
+	sem := make(chan struct{}, GetConcurrency())
+	go func() {
+		for idx, ic := range i.input {
+			p, ok := ic.(Prefetcher)
+			if !ok {
+				continue
+			}
+
+			select {
+			case sem <- struct{}{}:
+			case <-done: // stop prefetching once RunInput returns
+				return
+			}
+			go func() {
+				prefetched[idx] <- p.Prefetch()
+			}()
+		}
+	}()
+
+	for idx, ic := range i.input {
+		var err error
+		if prefetched[idx] != nil {
+			if err = <-prefetched[idx]; err == nil {
+				container, err = ic.Input(container)
+			}
+			<-sem
+		} else {
+			container, err = ic.Input(container)
+		}
 		if err != nil {
 			return err
 		}
@@ -99,13 +158,74 @@ func (i *instance) RunInput(container Container) error {
 }
 
 func (i *instance) RunOutput(container Container) error {
-	for _, oc := range i.output {
-		if err := oc.Output(container); err != nil {
-			return err
+	return ForEachConcurrently(groupOutputs(i.output), func(group []OutputConverter) error {
+		for _, oc := range group {
+			if err := oc.Output(container); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+}
+
+// groupOutputs groups outputs that must run one at a time in config order,
+// which are the ones writing to stdout, so that their output is not interleaved,
+// and the ones writing files in the same directory, as they may write the same files.
+// Groups are ordered by their first output.
+func groupOutputs(outputs []OutputConverter) [][]OutputConverter {
+	parent := make([]int, len(outputs))
+	for idx := range parent {
+		parent[idx] = idx
+	}
+	find := func(idx int) int {
+		for parent[idx] != idx {
+			idx = parent[idx]
+		}
+		return idx
 	}
 
-	return nil
+	// The first output writing to each target, where stdout is an empty target
+	owners := make(map[string]int)
+	for idx, oc := range outputs {
+		targets := make([]string, 0)
+		if so, ok := oc.(StdoutOutputter); ok && so.OutputsToStdout() {
+			targets = append(targets, "")
+		}
+		if fo, ok := oc.(FileOutputter); ok {
+			for _, file := range fo.OutputFiles() {
+				dir := filepath.Dir(file)
+				if abs, err := filepath.Abs(dir); err == nil {
+					dir = abs
+				}
+				targets = append(targets, dir)
+			}
+		}
+
+		for _, target := range targets {
+			owner, found := owners[target]
+			if !found {
+				owners[target] = idx
+				continue
+			}
+			a, b := find(owner), find(idx)
+			parent[max(a, b)] = min(a, b)
+		}
+	}
+
+	groups := make([][]OutputConverter, 0, len(outputs))
+	groupIndexes := make(map[int]int)
+	for idx, oc := range outputs {
+		root := find(idx)
+		groupIdx, found := groupIndexes[root]
+		if !found {
+			groupIdx = len(groups)
+			groupIndexes[root] = groupIdx
+			groups = append(groups, nil)
+		}
+		groups[groupIdx] = append(groups[groupIdx], oc)
+	}
+
+	return groups
 }
 
 func (i *instance) Run() error {
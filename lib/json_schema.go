@@ -0,0 +1,244 @@
+package lib
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema returns the JSON Schema of config file, generated from
+// registered converters and their schemas, for editors to autocomplete.
+func JSONSchema() map[string]any {
+	inputNames := make([]string, 0, len(inputConverterMap))
+	for name := range inputConverterMap {
+		inputNames = append(inputNames, name)
+	}
+	sort.Strings(inputNames)
+
+	outputNames := make([]string, 0, len(outputConverterMap))
+	for name := range outputConverterMap {
+		outputNames = append(outputNames, name)
+	}
+	sort.Strings(outputNames)
+
+	defs := map[string]any{
+		"http": httpConfigJSONSchema(),
+		"inputGroupReference": map[string]any{
+			"type":        "object",
+			"description": "Reference to an input group, replaced by its inputs",
+			"properties": map[string]any{
+				"group": map[string]any{"type": "string"},
+			},
+			"required":             []string{"group"},
+			"additionalProperties": false,
+		},
+	}
+
+	inputs := make([]any, 0, len(inputNames))
+	for _, name := range inputNames {
+		schema, _ := GetInputConverterSchema(name)
+		defs["input."+name] = converterJSONSchema(name, inputConverterMap[name].GetDescription(), schema, true)
+		inputs = append(inputs, map[string]any{"$ref": "#/$defs/input." + name})
+	}
+	defs["input"] = map[string]any{"oneOf": inputs}
+
+	outputs := make([]any, 0, len(outputNames))
+	for _, name := range outputNames {
+		schema, _ := GetOutputConverterSchema(name)
+		defs["output."+name] = converterJSONSchema(name, outputConverterMap[name].GetDescription(), schema, false)
+		outputs = append(outputs, map[string]any{"$ref": "#/$defs/output." + name})
+	}
+	defs["output"] = map[string]any{"oneOf": outputs}
+
+	return map[string]any{
+		"$schema": jsonSchemaDraft,
+		"title":   "geoip config",
+		"type":    "object",
+		"properties": map[string]any{
+			"$schema": map[string]any{"type": "string"},
+			"include": map[string]any{
+				"type":        "array",
+				"description": "Other config files to include",
+				"items":       map[string]any{"type": "string"},
+			},
+			"inputGroups": map[string]any{
+				"type":        "object",
+				"description": "Named groups of inputs",
+				"additionalProperties": map[string]any{
+					"type":  "array",
+					"items": map[string]any{"$ref": "#/$defs/input"},
+				},
+			},
+			"input": map[string]any{
+				"type": "array",
+				"items": map[string]any{
+					"anyOf": []any{
+						map[string]any{"$ref": "#/$defs/input"},
+						map[string]any{"$ref": "#/$defs/inputGroupReference"},
+					},
+				},
+			},
+			"output": map[string]any{
+				"type":  "array",
+				"items": map[string]any{"$ref": "#/$defs/output"},
+			},
+			"concurrency": map[string]any{
+				"type":        "integer",
+				"description": "Number of inputs and outputs to process concurrently",
+				"minimum":     1,
+			},
+			"http": map[string]any{"$ref": "#/$defs/http"},
+		},
+		"additionalProperties": false,
+		"$defs":                defs,
+	}
+}
+
+// converterJSONSchema returns the JSON Schema of an input or output in config file.
+// Args of converters without schema are not checked.
+func converterJSONSchema(name, description string, schema *ConverterSchema, isInput bool) map[string]any {
+	args := map[string]any{"type": "object"}
+	actions := []Action{ActionOutput}
+	if isInput {
+		actions = []Action{ActionAdd, ActionRemove}
+	}
+
+	if schema != nil {
+		actions = schema.Actions
+
+		properties := make(map[string]any, len(schema.Args))
+		required := make([]string, 0)
+		for _, arg := range schema.Args {
+			properties[arg.Name] = arg.jsonSchema()
+			if arg.Required {
+				required = append(required, arg.Name)
+			}
+		}
+
+		args["properties"] = properties
+		args["additionalProperties"] = false
+		if len(required) > 0 {
+			args["required"] = required
+		}
+	}
+
+	properties := map[string]any{
+		"type": map[string]any{
+			"type":     "string",
+			"pattern":  caseInsensitivePattern(name),
+			"examples": []string{name},
+		},
+		"action": map[string]any{"enum": actions},
+		"args":   args,
+	}
+
+	required := []string{"type", "action"}
+	if !isInput {
+		// Action of outputs defaults to output
+		required = []string{"type"}
+	}
+	if _, found := args["required"]; found {
+		required = append(required, "args")
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"description":          description,
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// caseInsensitivePattern returns the regular expression matching name case-insensitively,
+// as types in config file are, without the "i" flag not supported by JSON Schema.
+func caseInsensitivePattern(name string) string {
+	var pattern strings.Builder
+	pattern.WriteString("^")
+	for _, r := range name {
+		lower, upper := unicode.ToLower(r), unicode.ToUpper(r)
+		if lower == upper {
+			pattern.WriteString(regexp.QuoteMeta(string(r)))
+			continue
+		}
+		pattern.WriteString("[" + string(lower) + string(upper) + "]")
+	}
+	pattern.WriteString("$")
+	return pattern.String()
+}
+
+func (a *Arg) jsonSchema() map[string]any {
+	var schema map[string]any
+	switch a.Type {
+	case ArgTypeStringList:
+		schema = map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		}
+	case ArgTypeStringListOrMap:
+		list := map[string]any{
+			"type":  "array",
+			"items": map[string]any{"type": "string"},
+		}
+		schema = map[string]any{
+			"oneOf": []any{
+				list,
+				map[string]any{"type": "object", "additionalProperties": list},
+			},
+		}
+	case ArgTypeHTTP:
+		schema = map[string]any{"$ref": "#/$defs/http"}
+	default:
+		schema = map[string]any{"type": string(a.Type)}
+	}
+
+	if a.Description != "" {
+		schema["description"] = a.Description
+	}
+	if a.Default != nil {
+		schema["default"] = a.Default
+	}
+	if len(a.Enum) > 0 {
+		schema["enum"] = a.Enum
+	}
+
+	return schema
+}
+
+func httpConfigJSONSchema() map[string]any {
+	return map[string]any{
+		"type":        "object",
+		"description": "HTTP settings to fetch remote files",
+		"properties": map[string]any{
+			"timeout": map[string]any{
+				"type":        []string{"string", "number"},
+				"description": "Timeout of fetching each file, like \"30s\" or in seconds",
+			},
+			"retry": map[string]any{"type": "integer", "description": "Times to retry on network errors, 429 or 5xx"},
+			"retryBackoff": map[string]any{
+				"type":        []string{"string", "number"},
+				"description": "Wait before the first retry, doubled on each retry",
+			},
+			"proxy":     map[string]any{"type": "string", "description": "Proxy URL of http, https or socks5"},
+			"userAgent": map[string]any{"type": "string"},
+			"headers": map[string]any{
+				"type":                 "object",
+				"description":          "Extra HTTP headers",
+				"additionalProperties": map[string]any{"type": "string"},
+			},
+			"bearerToken": map[string]any{"type": "string"},
+			"basicAuth": map[string]any{
+				"type": "object",
+				"properties": map[string]any{
+					"username": map[string]any{"type": "string"},
+					"password": map[string]any{"type": "string"},
+				},
+				"additionalProperties": false,
+			},
+		},
+		"additionalProperties": false,
+	}
+}
@@ -0,0 +1,20 @@
+package lib
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestCaseInsensitivePattern(t *testing.T) {
+	pattern := regexp.MustCompile(caseInsensitivePattern("maxmindGeoLite2CountryCSV"))
+	for _, name := range []string{"maxmindGeoLite2CountryCSV", "MaxmindGeolite2CountryCsv", "MAXMINDGEOLITE2COUNTRYCSV"} {
+		if !pattern.MatchString(name) {
+			t.Errorf("pattern %s does not match %s", pattern, name)
+		}
+	}
+	for _, name := range []string{"maxmindGeoLite2CountryCSVIn", "xmaxmindGeoLite2CountryCSV", "maxmindGeoLite3CountryCSV"} {
+		if pattern.MatchString(name) {
+			t.Errorf("pattern %s matches %s", pattern, name)
+		}
+	}
+}
@@ -10,6 +10,9 @@ const (
 
 	CaseRemovePrefix CaseRemove = 0
 	CaseRemoveEntry  CaseRemove = 1
+
+	ContainmentFull    Containment = "full"
+	ContainmentPartial Containment = "partial"
 )
 
 var ActionsRegistry = map[Action]bool{
@@ -24,6 +27,8 @@ type IPType string
 
 type CaseRemove int
 
+type Containment string
+
 type Typer interface {
 	GetType() string
 }
@@ -50,6 +55,33 @@ type OutputConverter interface {
 	Output(Container) error
 }
 
+// Prefetcher is implemented by input converters whose time-consuming work,
+// like fetching and parsing files, does not depend on the container.
+// Prefetch may run concurrently with other converters before Input is called.
+type Prefetcher interface {
+	Prefetch() error
+}
+
+// FileOutputter is implemented by output converters writing files,
+// to list the files they will write in plan without running. Outputs
+// writing files in the same directory run one at a time in config order.
+type FileOutputter interface {
+	OutputFiles() []string
+}
+
+// ArgsResolver is implemented by converters normalizing their args or
+// filling them with defaults, to show the values in effect in plan
+// instead of the ones written in config file.
+type ArgsResolver interface {
+	ResolvedArgs() map[string]any
+}
+
+// StdoutOutputter is implemented by output converters writing to stdout,
+// which run one at a time in config order so that their output is not interleaved.
+type StdoutOutputter interface {
+	OutputsToStdout() bool
+}
+
 type IgnoreIPOption func() IPType
 
 func IgnoreIPv4() IPType {
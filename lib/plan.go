@@ -0,0 +1,279 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// hiddenValue replaces credentials in plan
+const hiddenValue = "***"
+
+// PlanStep describes a converter an instance will run, with its args in effect.
+type PlanStep struct {
+	Index  int            `json:"index"`
+	Type   string         `json:"type"`
+	Action Action         `json:"action"`
+	Args   map[string]any `json:"args"`
+
+	// Files the output will write
+	Files []string `json:"files,omitempty"`
+}
+
+// Plan is the pipeline an instance will run, without fetching or writing anything.
+type Plan struct {
+	Input  []*PlanStep `json:"input"`
+	Output []*PlanStep `json:"output"`
+}
+
+func (i *instance) Plan() *Plan {
+	plan := &Plan{
+		Input:  make([]*PlanStep, 0, len(i.input)),
+		Output: make([]*PlanStep, 0, len(i.output)),
+	}
+
+	for idx, ic := range i.input {
+		schema, _ := GetInputConverterSchema(ic.GetType())
+		plan.Input = append(plan.Input, &PlanStep{
+			Index:  idx + 1,
+			Type:   ic.GetType(),
+			Action: ic.GetAction(),
+			Args:   resolvePlanArgs(ic, schema, i.args[ic]),
+		})
+	}
+
+	for idx, oc := range i.output {
+		schema, _ := GetOutputConverterSchema(oc.GetType())
+		step := &PlanStep{
+			Index:  idx + 1,
+			Type:   oc.GetType(),
+			Action: oc.GetAction(),
+			Args:   resolvePlanArgs(oc, schema, i.args[oc]),
+		}
+		if fo, ok := oc.(FileOutputter); ok {
+			step.Files = fo.OutputFiles()
+		}
+		plan.Output = append(plan.Output, step)
+	}
+
+	return plan
+}
+
+// resolvePlanArgs returns args in effect of the converter, which are the ones
+// resolved by the converter, then the ones in config file, then the defaults in schema.
+func resolvePlanArgs(converter any, schema *ConverterSchema, data json.RawMessage) map[string]any {
+	args := getPlanArgs(data)
+
+	if schema != nil {
+		for _, arg := range schema.Args {
+			if arg.Default != nil && !hasPlanArg(args, arg.Name) {
+				args[arg.Name] = arg.Default
+			}
+		}
+	}
+
+	if resolver, ok := converter.(ArgsResolver); ok {
+		for name, value := range resolver.ResolvedArgs() {
+			// Args in config file are matched case-insensitively
+			for key := range args {
+				if strings.EqualFold(key, name) {
+					delete(args, key)
+				}
+			}
+			args[name] = redactValue(value)
+		}
+	}
+
+	return args
+}
+
+func hasPlanArg(args map[string]any, name string) bool {
+	for key := range args {
+		if strings.EqualFold(key, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListArgs returns the wanted and excluded lists and the IP types in effect,
+// for converters implementing ArgsResolver. List names are normalized to uppercase,
+// and lists both wanted and excluded are not wanted. If no list is wanted,
+// all lists except the excluded ones are processed, and wantedList is omitted.
+func ListArgs(want, exclude []string, onlyIPType IPType) map[string]any {
+	excluded := normalizeListNames(exclude)
+	wanted := slices.DeleteFunc(normalizeListNames(want), func(name string) bool {
+		return slices.Contains(excluded, name)
+	})
+
+	args := map[string]any{
+		"onlyIPType": IPTypesInEffect(onlyIPType),
+	}
+	if len(wanted) > 0 {
+		args["wantedList"] = wanted
+	}
+	if len(excluded) > 0 {
+		args["excludedList"] = excluded
+	}
+	return args
+}
+
+// IPTypesInEffect returns the IP types processed with onlyIPType,
+// which are both IPv4 and IPv6 unless onlyIPType is one of them.
+func IPTypesInEffect(onlyIPType IPType) []IPType {
+	switch onlyIPType {
+	case IPv4, IPv6:
+		return []IPType{onlyIPType}
+	default:
+		return []IPType{IPv4, IPv6}
+	}
+}
+
+func normalizeListNames(names []string) []string {
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+			normalized = append(normalized, name)
+		}
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized)
+}
+
+// getPlanArgs returns args of a converter as written in config file,
+// with variables expanded, and credentials of HTTP settings and URLs redacted.
+func getPlanArgs(data json.RawMessage) map[string]any {
+	args := make(map[string]any)
+	if len(bytes.TrimSpace(data)) == 0 {
+		return args
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	// Invalid args have already been rejected when creating the converter
+	if err := decoder.Decode(&args); err != nil || args == nil {
+		return make(map[string]any)
+	}
+
+	for name, value := range args {
+		if strings.EqualFold(name, "http") {
+			httpConfig := new(HTTPConfig)
+			if content, err := json.Marshal(value); err == nil && json.Unmarshal(content, httpConfig) == nil {
+				args[name] = httpConfig.redact()
+				continue
+			}
+		}
+		args[name] = redactValue(value)
+	}
+
+	return args
+}
+
+// redactValue returns value with credentials of URLs in it redacted.
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case string:
+		return redactURL(v)
+	case []any:
+		redacted := make([]any, 0, len(v))
+		for _, item := range v {
+			redacted = append(redacted, redactValue(item))
+		}
+		return redacted
+	case map[string]any:
+		redacted := make(map[string]any, len(v))
+		for key, item := range v {
+			redacted[key] = redactValue(item)
+		}
+		return redacted
+	}
+	return value
+}
+
+// redactURL hides the user info and query values of rawURL, which often contain
+// credentials like license keys. Strings other than URLs are returned as is.
+func redactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.User == nil && u.RawQuery == "") {
+		return rawURL
+	}
+
+	hasUser := u.User != nil
+	u.User = nil
+
+	if u.RawQuery != "" {
+		params := strings.Split(u.RawQuery, "&")
+		for idx, param := range params {
+			if key, _, found := strings.Cut(param, "="); found {
+				params[idx] = key + "=" + hiddenValue
+			} else if param != "" {
+				params[idx] = hiddenValue
+			}
+		}
+		u.RawQuery = strings.Join(params, "&")
+	}
+
+	// Set user info as is, which would be escaped by url.User
+	if hasUser {
+		return strings.Replace(u.String(), "://", "://"+hiddenValue+"@", 1)
+	}
+	return u.String()
+}
+
+// redact returns a copy of the HTTPConfig with credentials and header values hidden.
+func (h *HTTPConfig) redact() *HTTPConfig {
+	redacted := h.Merge(nil)
+	if redacted.Proxy != "" {
+		redacted.Proxy = redactURL(redacted.Proxy)
+	}
+	if redacted.BearerToken != "" {
+		redacted.BearerToken = hiddenValue
+	}
+	if redacted.BasicAuth != nil {
+		redacted.BasicAuth = &BasicAuth{
+			Username: redacted.BasicAuth.Username,
+			Password: hiddenValue,
+		}
+	}
+	if len(redacted.Headers) > 0 {
+		headers := make(map[string]string, len(redacted.Headers))
+		for key := range redacted.Headers {
+			headers[key] = hiddenValue
+		}
+		redacted.Headers = headers
+	}
+
+	return redacted
+}
+
+// ListFiles returns paths of files in dir named after lists with ext,
+// for outputs writing one file per list. The lists are the wanted lists
+// except the excluded ones, or <list> if all lists are wanted, which are
+// not known before running inputs.
+func ListFiles(dir, ext string, want, exclude []string) []string {
+	excludeMap := make(map[string]bool)
+	for _, name := range exclude {
+		excludeMap[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+
+	names := make([]string, 0, len(want))
+	for _, name := range want {
+		if name = strings.ToLower(strings.TrimSpace(name)); name != "" && !excludeMap[name] {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		names = append(names, "<list>")
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+
+	files := make([]string, 0, len(names))
+	for _, name := range names {
+		files = append(files, filepath.Join(dir, name+ext))
+	}
+	return files
+}
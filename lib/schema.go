@@ -0,0 +1,258 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ArgType string
+
+const (
+	ArgTypeString     ArgType = "string"
+	ArgTypeBoolean    ArgType = "boolean"
+	ArgTypeInteger    ArgType = "integer"
+	ArgTypeStringList ArgType = "[]string"
+
+	// ArgTypeStringListOrMap is either a list of strings,
+	// or a map from list names to lists of strings.
+	ArgTypeStringListOrMap ArgType = "[]string | map[string][]string"
+
+	// ArgTypeHTTP is the same as the global http settings in config file.
+	ArgTypeHTTP ArgType = "http"
+)
+
+// Arg describes an arg of a converter in config file.
+type Arg struct {
+	Name        string   `json:"name"`
+	Type        ArgType  `json:"type"`
+	Description string   `json:"description"`
+	Default     any      `json:"default,omitempty"`
+	Required    bool     `json:"required,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+}
+
+// ConverterSchema describes the actions and args a converter supports.
+type ConverterSchema struct {
+	Actions []Action `json:"actions"`
+	Args    []*Arg   `json:"args"`
+
+	// Example args in config file
+	Example map[string]any `json:"example,omitempty"`
+}
+
+// Arg returns the arg with the name, or nil if not found.
+func (s *ConverterSchema) Arg(name string) *Arg {
+	for _, arg := range s.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+func (s *ConverterSchema) SupportsAction(action Action) bool {
+	return slices.Contains(s.Actions, action)
+}
+
+var (
+	inputSchemaCache  = make(map[string]*ConverterSchema)
+	outputSchemaCache = make(map[string]*ConverterSchema)
+)
+
+func RegisterInputConverterSchema(id string, schema *ConverterSchema) error {
+	id = strings.ToLower(id)
+	if _, found := inputSchemaCache[id]; found {
+		return errors.New("converter schema has already been registered")
+	}
+	inputSchemaCache[id] = schema
+	return nil
+}
+
+func GetInputConverterSchema(id string) (*ConverterSchema, bool) {
+	schema, found := inputSchemaCache[strings.ToLower(id)]
+	return schema, found
+}
+
+func RegisterOutputConverterSchema(id string, schema *ConverterSchema) error {
+	id = strings.ToLower(id)
+	if _, found := outputSchemaCache[id]; found {
+		return errors.New("converter schema has already been registered")
+	}
+	outputSchemaCache[id] = schema
+	return nil
+}
+
+func GetOutputConverterSchema(id string) (*ConverterSchema, bool) {
+	schema, found := outputSchemaCache[strings.ToLower(id)]
+	return schema, found
+}
+
+// Args shared by many converters
+
+func ArgOnlyIPType() *Arg {
+	return &Arg{
+		Name:        "onlyIPType",
+		Type:        ArgTypeString,
+		Description: "The only IP type to process",
+		Enum:        []string{string(IPv4), string(IPv6)},
+	}
+}
+
+func ArgWantedList() *Arg {
+	return &Arg{
+		Name:        "wantedList",
+		Type:        ArgTypeStringList,
+		Description: "The only lists to process",
+	}
+}
+
+func ArgExcludedList() *Arg {
+	return &Arg{
+		Name:        "excludedList",
+		Type:        ArgTypeStringList,
+		Description: "The lists not to output",
+	}
+}
+
+func ArgHTTP() *Arg {
+	return &Arg{
+		Name:        "http",
+		Type:        ArgTypeHTTP,
+		Description: "HTTP settings to fetch remote files, overriding the global ones",
+	}
+}
+
+// ArgsChecksum returns args of the expected checksum of local or remote files.
+func ArgsChecksum() []*Arg {
+	return []*Arg{
+		{
+			Name:        "sha256",
+			Type:        ArgTypeString,
+			Description: "The expected SHA-256 checksum of the file",
+		},
+		{
+			Name:        "sha256URI",
+			Type:        ArgTypeString,
+			Description: "Path or URL of a checksum file in the format of sha256sum",
+		},
+	}
+}
+
+// Whether to reject unknown fields in config file
+var strict bool
+
+func SetStrict(enabled bool) {
+	strict = enabled
+}
+
+// checkFields returns an error if the JSON object in data has fields other than allowed.
+func checkFields(data []byte, kind string, allowed ...string) error {
+	if len(bytes.TrimSpace(data)) == 0 || string(bytes.TrimSpace(data)) == "null" {
+		return nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	unknown := make([]string, 0)
+	for name := range fields {
+		if !slices.Contains(allowed, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	slices.Sort(unknown)
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	messages := make([]string, 0, len(unknown))
+	for _, name := range unknown {
+		if suggestion := suggestField(name, allowed); suggestion != "" {
+			messages = append(messages, fmt.Sprintf("unknown %s %s (did you mean %s?)", kind, name, suggestion))
+		} else {
+			messages = append(messages, fmt.Sprintf("unknown %s %s", kind, name))
+		}
+	}
+
+	return errors.New(strings.Join(messages, "; "))
+}
+
+// checkHTTPConfigFields returns an error if the HTTP settings in data have unknown fields.
+func checkHTTPConfigFields(data []byte) error {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(new(HTTPConfig)); err != nil {
+		return fmt.Errorf("http: %w", err)
+	}
+	return nil
+}
+
+// checkArgs returns an error if args in data are not described by the schema.
+func (s *ConverterSchema) checkArgs(data []byte) error {
+	names := make([]string, 0, len(s.Args))
+	for _, arg := range s.Args {
+		names = append(names, arg.Name)
+	}
+	if err := checkFields(data, "arg", names...); err != nil {
+		return err
+	}
+
+	var args map[string]json.RawMessage
+	json.Unmarshal(data, &args)
+	for name, value := range args {
+		if s.Arg(name).Type == ArgTypeHTTP {
+			if err := checkHTTPConfigFields(value); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// suggestField returns the allowed field most similar to name, or empty if none is similar enough.
+func suggestField(name string, allowed []string) string {
+	suggestion := ""
+	minDistance := max(2, len(name)/3) + 1
+	for _, field := range allowed {
+		if distance := editDistance(strings.ToLower(name), strings.ToLower(field)); distance < minDistance {
+			suggestion = field
+			minDistance = distance
+		}
+	}
+	return suggestion
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Parameters to expand in config files, which take precedence over environment variables
+var configParams = make(map[string]string)
+
+func SetConfigParams(params map[string]string) {
+	configParams = params
+}
+
+// expandConfigTemplate expands ${VAR} and ${VAR:-default} in all string values
+// of the config file in JSON format, with parameters or environment variables.
+func expandConfigTemplate(content []byte) ([]byte, error) {
+	if !bytes.Contains(content, []byte("${")) {
+		return content, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	// Keep numbers as they are
+	decoder.UseNumber()
+
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	value, err := expandTemplateValue(value)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(value)
+}
+
+func expandTemplateValue(value any) (any, error) {
+	switch value := value.(type) {
+	case string:
+		return expandTemplate(value)
+
+	case []any:
+		for i, item := range value {
+			expanded, err := expandTemplateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			value[i] = expanded
+		}
+
+	case map[string]any:
+		for key, item := range value {
+			expanded, err := expandTemplateValue(item)
+			if err != nil {
+				return nil, err
+			}
+			value[key] = expanded
+		}
+	}
+
+	return value, nil
+}
+
+// expandTemplate expands ${VAR} and ${VAR:-default} in s. The default value is used
+// when the variable is not set or empty, and a variable without default value must be set.
+// $${VAR} is kept as ${VAR} literally.
+func expandTemplate(s string) (string, error) {
+	var sb strings.Builder
+
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			sb.WriteString(s)
+			break
+		}
+
+		// Escaped by a leading $
+		if start > 0 && s[start-1] == '$' {
+			sb.WriteString(s[:start-1])
+			sb.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("unclosed variable in %q", s)
+		}
+		end += start
+
+		sb.WriteString(s[:start])
+
+		name, defaultValue, hasDefault := strings.Cut(s[start+2:end], ":-")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return "", fmt.Errorf("empty variable name in %q", s)
+		}
+
+		value, found := configParams[name]
+		if !found {
+			value, found = os.LookupEnv(name)
+		}
+		switch {
+		case hasDefault && value == "":
+			value = defaultValue
+		case !found:
+			return "", fmt.Errorf("variable %s is not set", name)
+		}
+		sb.WriteString(value)
+
+		s = s[end+1:]
+	}
+
+	return sb.String(), nil
+}
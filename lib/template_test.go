@@ -0,0 +1,82 @@
+package lib
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExpandTemplate(t *testing.T) {
+	defer SetConfigParams(make(map[string]string))
+	SetConfigParams(map[string]string{
+		"PARAM":     "param",
+		"OVERRIDE":  "from-param",
+		"EMPTY_SET": "",
+	})
+	t.Setenv("ENV", "env")
+	t.Setenv("OVERRIDE", "from-env")
+	t.Setenv("EMPTY_ENV", "")
+
+	tests := []struct {
+		s       string
+		want    string
+		wantErr string
+	}{
+		{s: "no variable", want: "no variable"},
+		{s: "${PARAM}", want: "param"},
+		{s: "${ENV}", want: "env"},
+		{s: "${ OVERRIDE }", want: "from-param"},
+		{s: "./output/${PARAM}/${ENV}.dat", want: "./output/param/env.dat"},
+		{s: "${UNSET:-default}", want: "default"},
+		{s: "${UNSET:-}", want: ""},
+		{s: "${EMPTY_SET:-default}", want: "default"},
+		{s: "${EMPTY_ENV:-default}", want: "default"},
+		{s: "${EMPTY_ENV}", want: ""},
+		{s: "${PARAM:-default}", want: "param"},
+		{s: "${UNSET:-a:-b}", want: "a:-b"},
+		{s: "$${PARAM}", want: "${PARAM}"},
+		{s: "$$${PARAM}", want: "$${PARAM}"},
+		{s: "price: $5", want: "price: $5"},
+		{s: "${UNSET}", wantErr: "variable UNSET is not set"},
+		{s: "${}", wantErr: "empty variable name"},
+		{s: "${PARAM", wantErr: "unclosed variable"},
+	}
+
+	for _, tt := range tests {
+		got, err := expandTemplate(tt.s)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expandTemplate(%q) error = %v, want error containing %q", tt.s, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("expandTemplate(%q) error = %v", tt.s, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("expandTemplate(%q) = %q, want %q", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestExpandConfigTemplate(t *testing.T) {
+	defer SetConfigParams(make(map[string]string))
+	SetConfigParams(map[string]string{"DIR": "./release", "LIST": "cn"})
+
+	content := []byte(`{"concurrency": 12345678901234567890, "output": [{"type": "text", "args": {"outputDir": "${DIR}", "wantedList": ["${LIST}", "private"]}}]}`)
+	got, err := expandConfigTemplate(content)
+	if err != nil {
+		t.Fatalf("expandConfigTemplate() error = %v", err)
+	}
+
+	want := `{"concurrency":12345678901234567890,"output":[{"args":{"outputDir":"./release","wantedList":["cn","private"]},"type":"text"}]}`
+	if string(got) != want {
+		t.Errorf("expandConfigTemplate() = %s, want %s", got, want)
+	}
+
+	// Config files without variables are kept as they are
+	content = []byte(`{"input": []}`)
+	if got, err := expandConfigTemplate(content); err != nil || string(got) != string(content) {
+		t.Errorf("expandConfigTemplate(%s) = %s, %v, want it unchanged", content, got, err)
+	}
+}
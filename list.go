@@ -1,21 +1,137 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
 	"github.com/Loyalsoldier/geoip/lib"
 	"github.com/spf13/cobra"
 )
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+	listCmd.Flags().Bool("json", false, "Print actions, args and examples of formats in JSON format")
 }
 
 var listCmd = &cobra.Command{
-	Use:     "list",
+	Use:     "list [type...]",
 	Aliases: []string{"l", "ls"},
-	Short:   "List all available input and output formats",
+	Short:   "List all available input and output formats, or actions, args and examples of specified formats",
 	Run: func(cmd *cobra.Command, args []string) {
-		lib.ListInputConverter()
-		println()
-		lib.ListOutputConverter()
+		printJSON, _ := cmd.Flags().GetBool("json")
+
+		if len(args) == 0 && !printJSON {
+			lib.ListInputConverter()
+			println()
+			lib.ListOutputConverter()
+			return
+		}
+
+		result := &converterInfos{
+			Input:  filterConverterInfos(lib.GetInputConverterInfos(), args),
+			Output: filterConverterInfos(lib.GetOutputConverterInfos(), args),
+		}
+
+		for _, name := range args {
+			if !result.contains(name) {
+				log.Fatalf("❌ format %s not found\n", name)
+			}
+		}
+
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			encoder.SetEscapeHTML(false)
+			if err := encoder.Encode(result); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		for _, info := range result.Input {
+			printConverterInfo("input", info)
+		}
+		for _, info := range result.Output {
+			printConverterInfo("output", info)
+		}
 	},
 }
+
+type converterInfos struct {
+	Input  []*lib.ConverterInfo `json:"input"`
+	Output []*lib.ConverterInfo `json:"output"`
+}
+
+func (c *converterInfos) contains(name string) bool {
+	for _, infos := range [][]*lib.ConverterInfo{c.Input, c.Output} {
+		for _, info := range infos {
+			if strings.EqualFold(info.Name, strings.TrimSpace(name)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterConverterInfos returns infos of the formats with names,
+// case-insensitively, or all infos if names is empty.
+func filterConverterInfos(infos []*lib.ConverterInfo, names []string) []*lib.ConverterInfo {
+	if len(names) == 0 {
+		return infos
+	}
+
+	filtered := make([]*lib.ConverterInfo, 0, len(names))
+	for _, info := range infos {
+		for _, name := range names {
+			if strings.EqualFold(info.Name, strings.TrimSpace(name)) {
+				filtered = append(filtered, info)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func printConverterInfo(kind string, info *lib.ConverterInfo) {
+	fmt.Printf("%s (%s format)\n", info.Name, kind)
+	fmt.Printf("  %s\n\n", info.Description)
+
+	actions := make([]string, 0, len(info.Actions))
+	for _, action := range info.Actions {
+		actions = append(actions, string(action))
+	}
+	fmt.Printf("Actions: %s\n\n", strings.Join(actions, ", "))
+
+	if len(info.Args) > 0 {
+		fmt.Println("Args:")
+		for _, arg := range info.Args {
+			attrs := []string{string(arg.Type)}
+			if arg.Required {
+				attrs = append(attrs, "required")
+			}
+			if arg.Default != nil {
+				value, _ := json.Marshal(arg.Default)
+				attrs = append(attrs, "default: "+string(value))
+			}
+			if len(arg.Enum) > 0 {
+				attrs = append(attrs, "one of: "+strings.Join(arg.Enum, ", "))
+			}
+			fmt.Printf("  %-22s %s (%s)\n", arg.Name, arg.Description, strings.Join(attrs, "; "))
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("Example:")
+	var example bytes.Buffer
+	encoder := json.NewEncoder(&example)
+	encoder.SetIndent("  ", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(info.Example); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("  %s\n", example.String())
+}
@@ -6,43 +6,37 @@ import (
 	"log"
 	"net/netip"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/Loyalsoldier/geoip/lib"
-	"github.com/Loyalsoldier/geoip/plugin/maxmind"
-	"github.com/Loyalsoldier/geoip/plugin/mihomo"
-	"github.com/Loyalsoldier/geoip/plugin/plaintext"
-	"github.com/Loyalsoldier/geoip/plugin/singbox"
 	"github.com/Loyalsoldier/geoip/plugin/special"
-	"github.com/Loyalsoldier/geoip/plugin/v2ray"
 	"github.com/spf13/cobra"
 )
 
-var supportedInputFormats = map[string]bool{
-	strings.ToLower("clashRuleSet"):          true,
-	strings.ToLower("clashRuleSetClassical"): true,
-	strings.ToLower("dbipCountryMMDB"):       true,
-	strings.ToLower("ipinfoCountryMMDB"):     true,
-	strings.ToLower("maxmindMMDB"):           true,
-	strings.ToLower("mihomoMRS"):             true,
-	strings.ToLower("singboxSRS"):            true,
-	strings.ToLower("surgeRuleSet"):          true,
-	strings.ToLower("text"):                  true,
-	strings.ToLower("v2rayGeoIPDat"):         true,
-}
+// The list name used by input formats that need a name, like text,
+// when reading a single file, which lookup prints if the IP is in it
+const defaultListName = "true"
 
 func init() {
 	rootCmd.AddCommand(lookupCmd)
 
-	lookupCmd.Flags().StringP("format", "f", "", "(Required) The input format. Available formats: text, v2rayGeoIPDat, maxmindMMDB, dbipCountryMMDB, ipinfoCountryMMDB, mihomoMRS, singboxSRS, clashRuleSet, clashRuleSetClassical, surgeRuleSet")
-	lookupCmd.Flags().StringP("uri", "u", "", "URI of the input file, support both local file path and remote HTTP(S) URL. (Cannot be used with \"dir\" flag)")
-	lookupCmd.Flags().StringP("dir", "d", "", "Path to the input directory. The filename without extension will be as the name of the list. (Cannot be used with \"uri\" flag)")
+	addInputFlags(lookupCmd)
 	lookupCmd.Flags().StringSliceP("searchlist", "l", []string{}, "The lists to search from, separated by comma")
-
-	lookupCmd.MarkFlagRequired("format")
-	lookupCmd.MarkFlagsOneRequired("uri", "dir")
-	lookupCmd.MarkFlagsMutuallyExclusive("uri", "dir")
-	lookupCmd.MarkFlagDirname("dir")
+	lookupCmd.Flags().Duration("reloadinterval", 5*time.Second, "In REPL mode, the interval to check if local input files changed, and reload them if so (0 to disable)")
+	lookupCmd.Flags().Bool("detail", false, "Print the matched prefix of each list, and whether the CIDR is fully or partially in it, in JSON format")
+	lookupCmd.Flags().Bool("overlap", false, "Print every list intersecting the CIDR, with the intersected prefixes and the fraction of addresses covered, in JSON format")
+	lookupCmd.Flags().StringP("input", "i", "", "Run in bulk mode, read IPs or CIDRs line by line from the file, or from stdin if it is \"-\"")
+	lookupCmd.Flags().StringP("output", "o", "", "In bulk mode, the file to write results to (default is stdout)")
+	lookupCmd.Flags().String("outputformat", "csv", "In bulk mode, the format of results. Available formats: csv, jsonl")
+	lookupCmd.Flags().Bool("onlymiss", false, "In bulk mode, only write IPs or CIDRs not found in any list, skipping invalid ones")
+
+	lookupCmd.MarkFlagsMutuallyExclusive("detail", "overlap")
 }
 
 var lookupCmd = &cobra.Command{
@@ -51,24 +45,40 @@ var lookupCmd = &cobra.Command{
 	Short:   "Lookup if specified IP or CIDR is in specified lists",
 	Args:    cobra.RangeArgs(0, 1),
 	Run: func(cmd *cobra.Command, args []string) {
-		// Validate format
-		format, _ := cmd.Flags().GetString("format")
-		format = strings.ToLower(strings.TrimSpace(format))
-		if _, found := supportedInputFormats[format]; !found {
-			log.Fatal("unsupported input format")
-		}
+		// Get input, the name of list is "true" for input formats that need a name
+		source := getInputSource(cmd, defaultListName)
+
+		// Get searchlist
+		searchList, _ := cmd.Flags().GetStringSlice("searchlist")
 
-		// Set name
-		name := "true"
+		// Get detail and overlap
+		detail, _ := cmd.Flags().GetBool("detail")
+		overlap, _ := cmd.Flags().GetBool("overlap")
 
-		// Get uri
-		uri, _ := cmd.Flags().GetString("uri")
+		// With input flag, run in bulk mode
+		if input, _ := cmd.Flags().GetString("input"); input != "" {
+			if len(args) > 0 {
+				log.Fatal("cannot lookup argument in bulk mode")
+			}
+			if detail || overlap {
+				log.Fatal(`"detail" and "overlap" flags cannot be used in bulk mode`)
+			}
 
-		// Get dir
-		dir, _ := cmd.Flags().GetString("dir")
+			output, _ := cmd.Flags().GetString("output")
+			outputFormat, _ := cmd.Flags().GetString("outputformat")
+			onlyMiss, _ := cmd.Flags().GetBool("onlymiss")
 
-		// Get searchlist
-		searchList, _ := cmd.Flags().GetStringSlice("searchlist")
+			if err := runBulkLookup(source, input, output, outputFormat, searchList, onlyMiss); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		for _, name := range []string{"output", "outputformat", "onlymiss"} {
+			if cmd.Flags().Changed(name) {
+				log.Fatalf(`"%s" flag can only be used in bulk mode with "input" flag`, name)
+			}
+		}
 
 		switch len(args) > 0 {
 		case true: // With search arg, run in once mode
@@ -78,31 +88,26 @@ var lookupCmd = &cobra.Command{
 				return
 			}
 
-			instance, err := lib.NewInstance()
+			instance, err := source.newInstance()
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			instance.AddInput(getInputForLookup(format, name, uri, dir))
-			instance.AddOutput(getOutputForLookup(search, searchList...))
+			instance.AddOutput(getOutputForLookup(search, detail, overlap, searchList...))
 
 			if err := instance.Run(); err != nil {
 				log.Fatal(err)
 			}
 
 		case false: // No search arg, run in REPL mode
-			instance, err := lib.NewInstance()
+			interval, _ := cmd.Flags().GetDuration("reloadinterval")
+			repl, err := newLookupREPL(source)
 			if err != nil {
 				log.Fatal(err)
 			}
-			instance.AddInput(getInputForLookup(format, name, uri, dir))
-
-			container := lib.NewContainer()
-			if err := instance.RunInput(container); err != nil {
-				log.Fatal(err)
-			}
+			repl.watch(interval)
 
-			fmt.Println(`Enter IP or CIDR (type "exit" to quit):`)
+			fmt.Println(`Enter IP or CIDR (type "reload" to reload data, "exit" to quit):`)
 			fmt.Print(">> ")
 
 			scanner := bufio.NewScanner(os.Stdin)
@@ -116,6 +121,12 @@ var lookupCmd = &cobra.Command{
 				if search == "exit" || search == `"exit"` {
 					break
 				}
+				if search == "reload" || search == `"reload"` {
+					repl.reload()
+					fmt.Println()
+					fmt.Print(">> ")
+					continue
+				}
 
 				if !isValidIPOrCIDR(search) {
 					fmt.Println("false")
@@ -124,10 +135,7 @@ var lookupCmd = &cobra.Command{
 					continue
 				}
 
-				instance.ResetOutput()
-				instance.AddOutput(getOutputForLookup(search, searchList...))
-
-				if err := instance.RunOutput(container); err != nil {
+				if err := repl.lookup(search, detail, overlap, searchList...); err != nil {
 					log.Fatal(err)
 				}
 
@@ -141,6 +149,152 @@ var lookupCmd = &cobra.Command{
 	},
 }
 
+// lookupREPL holds the container of the REPL mode, which can be reloaded
+// and swapped atomically without affecting lookups in progress.
+type lookupREPL struct {
+	source *inputSource
+
+	container atomic.Pointer[lib.Container]
+	reloadMu  sync.Mutex // serializes reloads
+	signature string     // signature of local source files when last loaded
+}
+
+func newLookupREPL(source *inputSource) (*lookupREPL, error) {
+	r := &lookupREPL{
+		source: source,
+	}
+
+	r.signature, _ = r.sourceSignature()
+	container, err := loadFrozenContainer(r.source)
+	if err != nil {
+		return nil, err
+	}
+	r.container.Store(&container)
+
+	return r, nil
+}
+
+func (r *lookupREPL) lookup(search string, detail, overlap bool, searchList ...string) error {
+	instance, err := lib.NewInstance()
+	if err != nil {
+		return err
+	}
+	instance.AddOutput(getOutputForLookup(search, detail, overlap, searchList...))
+
+	return instance.RunOutput(*r.container.Load())
+}
+
+// reload loads data again and swaps the container if succeeded,
+// then prints what changed.
+func (r *lookupREPL) reload() {
+	r.reloadMu.Lock()
+	defer r.reloadMu.Unlock()
+
+	signature, _ := r.sourceSignature()
+	container, err := loadFrozenContainer(r.source)
+	if err != nil {
+		log.Printf("❌ failed to reload data, keep using the old one: %v\n", err)
+		return
+	}
+
+	oldContainer := *r.container.Swap(&container)
+	r.signature = signature
+
+	result, err := diffContainers(oldContainer, container, nil, false)
+	if err != nil {
+		log.Printf("❌ failed to compare reloaded data: %v\n", err)
+		return
+	}
+
+	fmt.Println("Data reloaded")
+	result.print()
+}
+
+// watch reloads data on SIGHUP, and when local source files change,
+// which are checked every interval. Zero interval disables checking.
+func (r *lookupREPL) watch(interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	var tick <-chan time.Time
+	if interval > 0 && r.signature != "" {
+		tick = time.NewTicker(interval).C
+	}
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+			case <-tick:
+				signature, err := r.sourceSignature()
+				r.reloadMu.Lock()
+				changed := err == nil && signature != r.signature
+				r.reloadMu.Unlock()
+				if !changed {
+					continue
+				}
+			}
+
+			fmt.Println()
+			r.reload()
+			fmt.Println()
+			fmt.Print(">> ")
+		}
+	}()
+}
+
+// sourceSignature returns a string that changes when the local source files change.
+// An empty string is returned for remote source. Only the config file itself is
+// checked when the input is specified by a config file.
+func (r *lookupREPL) sourceSignature() (string, error) {
+	var sb strings.Builder
+	appendFileInfo := func(path string, info os.FileInfo) {
+		fmt.Fprintf(&sb, "%s|%d|%d\n", path, info.Size(), info.ModTime().UnixNano())
+	}
+
+	uri := r.source.uri
+	if r.source.config != "" {
+		uri = r.source.config
+	}
+
+	switch {
+	case r.source.dir != "":
+		err := filepath.Walk(r.source.dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				appendFileInfo(path, info)
+			}
+			return nil
+		})
+		if err != nil {
+			return "", err
+		}
+
+	case uri == "", strings.HasPrefix(strings.ToLower(uri), "http://"), strings.HasPrefix(strings.ToLower(uri), "https://"):
+		return "", nil
+
+	default:
+		path := uri
+		info, err := os.Stat(path)
+		if err != nil {
+			// Strip the member selected in an archive
+			index := strings.LastIndex(path, "#")
+			if index < 0 {
+				return "", err
+			}
+			path = path[:index]
+			if info, err = os.Stat(path); err != nil {
+				return "", err
+			}
+		}
+		appendFileInfo(path, info)
+	}
+
+	return sb.String(), nil
+}
+
 // Check if the input is a valid IP or CIDR
 func isValidIPOrCIDR(search string) bool {
 	if search == "" {
@@ -158,115 +312,44 @@ func isValidIPOrCIDR(search string) bool {
 	return err == nil
 }
 
-func getInputForLookup(format, name, uri, dir string) lib.InputConverter {
-	var input lib.InputConverter
-
-	switch strings.ToLower(format) {
-	case strings.ToLower(maxmind.TypeGeoLite2CountryMMDBIn):
-		input = &maxmind.GeoLite2CountryMMDBIn{
-			Type:        maxmind.TypeGeoLite2CountryMMDBIn,
-			Action:      lib.ActionAdd,
-			Description: maxmind.DescGeoLite2CountryMMDBIn,
-			URI:         uri,
-		}
-
-	case strings.ToLower(maxmind.TypeDBIPCountryMMDBIn):
-		input = &maxmind.GeoLite2CountryMMDBIn{
-			Type:        maxmind.TypeDBIPCountryMMDBIn,
-			Action:      lib.ActionAdd,
-			Description: maxmind.DescDBIPCountryMMDBIn,
-			URI:         uri,
-		}
-
-	case strings.ToLower(maxmind.TypeIPInfoCountryMMDBIn):
-		input = &maxmind.GeoLite2CountryMMDBIn{
-			Type:        maxmind.TypeIPInfoCountryMMDBIn,
-			Action:      lib.ActionAdd,
-			Description: maxmind.DescIPInfoCountryMMDBIn,
-			URI:         uri,
-		}
-
-	case strings.ToLower(mihomo.TypeMRSIn):
-		input = &mihomo.MRSIn{
-			Type:        mihomo.TypeMRSIn,
-			Action:      lib.ActionAdd,
-			Description: mihomo.DescMRSIn,
-			Name:        name,
-			URI:         uri,
-			InputDir:    dir,
-		}
-
-	case strings.ToLower(singbox.TypeSRSIn):
-		input = &singbox.SRSIn{
-			Type:        singbox.TypeSRSIn,
-			Action:      lib.ActionAdd,
-			Description: singbox.DescSRSIn,
-			Name:        name,
-			URI:         uri,
-			InputDir:    dir,
-		}
-
-	case strings.ToLower(v2ray.TypeGeoIPDatIn):
-		input = &v2ray.GeoIPDatIn{
-			Type:        v2ray.TypeGeoIPDatIn,
-			Action:      lib.ActionAdd,
-			Description: v2ray.DescGeoIPDatIn,
-			URI:         uri,
-		}
-
-	case strings.ToLower(plaintext.TypeTextIn):
-		input = &plaintext.TextIn{
-			Type:        plaintext.TypeTextIn,
-			Action:      lib.ActionAdd,
-			Description: plaintext.DescTextIn,
-			Name:        name,
-			URI:         uri,
-			InputDir:    dir,
-		}
+// loadContainer reads data of the input source into a new container.
+func loadContainer(source *inputSource) (lib.Container, error) {
+	instance, err := source.newInstance()
+	if err != nil {
+		return nil, err
+	}
 
-	case strings.ToLower(plaintext.TypeClashRuleSetIPCIDRIn):
-		input = &plaintext.TextIn{
-			Type:        plaintext.TypeClashRuleSetIPCIDRIn,
-			Action:      lib.ActionAdd,
-			Description: plaintext.DescClashRuleSetIPCIDRIn,
-			Name:        name,
-			URI:         uri,
-			InputDir:    dir,
-		}
+	container := lib.NewContainer()
+	if err := instance.RunInput(container); err != nil {
+		return nil, err
+	}
 
-	case strings.ToLower(plaintext.TypeClashRuleSetClassicalIn):
-		input = &plaintext.TextIn{
-			Type:        plaintext.TypeClashRuleSetClassicalIn,
-			Action:      lib.ActionAdd,
-			Description: plaintext.DescClashRuleSetClassicalIn,
-			Name:        name,
-			URI:         uri,
-			InputDir:    dir,
-		}
+	return container, nil
+}
 
-	case strings.ToLower(plaintext.TypeSurgeRuleSetIn):
-		input = &plaintext.TextIn{
-			Type:        plaintext.TypeSurgeRuleSetIn,
-			Action:      lib.ActionAdd,
-			Description: plaintext.DescSurgeRuleSetIn,
-			Name:        name,
-			URI:         uri,
-			InputDir:    dir,
-		}
+// loadFrozenContainer is like loadContainer, but the container is frozen
+// to speed up lookups for long-running commands.
+func loadFrozenContainer(source *inputSource) (lib.Container, error) {
+	container, err := loadContainer(source)
+	if err != nil {
+		return nil, err
+	}
 
-	default:
-		log.Fatal("unsupported input format")
+	if err := container.Freeze(); err != nil {
+		return nil, err
 	}
 
-	return input
+	return container, nil
 }
 
-func getOutputForLookup(search string, searchList ...string) lib.OutputConverter {
+func getOutputForLookup(search string, detail, overlap bool, searchList ...string) lib.OutputConverter {
 	return &special.Lookup{
 		Type:        special.TypeLookup,
 		Action:      lib.ActionOutput,
 		Description: special.DescLookup,
 		Search:      search,
 		SearchList:  searchList,
+		Detail:      detail,
+		Overlap:     overlap,
 	}
 }
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+// runBulkLookup looks up IPs or CIDRs read line by line from input,
+// and writes results to output in CSV or JSON Lines format.
+// Empty lines and lines starting with "#" are skipped. Invalid lines are
+// written with an error, or logged to stderr and skipped if onlyMiss is true.
+func runBulkLookup(source *inputSource, input, output, outputFormat string, searchList []string, onlyMiss bool) error {
+	outputFormat = strings.ToLower(strings.TrimSpace(outputFormat))
+	switch outputFormat {
+	case "csv", "jsonl":
+	default:
+		return fmt.Errorf("unsupported output format %s, available formats: csv, jsonl", outputFormat)
+	}
+
+	container, err := loadFrozenContainer(source)
+	if err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if input != "-" {
+		f, err := os.Open(input)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	bw := bufio.NewWriter(w)
+	if err := bulkLookup(container, r, bw, outputFormat, searchList, onlyMiss); err != nil {
+		return err
+	}
+
+	return bw.Flush()
+}
+
+func bulkLookup(container lib.Container, r io.Reader, w io.Writer, outputFormat string, searchList []string, onlyMiss bool) error {
+	var write func(result *lookupResponse) error
+	switch outputFormat {
+	case "jsonl":
+		encoder := json.NewEncoder(w)
+		write = func(result *lookupResponse) error {
+			return encoder.Encode(result)
+		}
+
+	default:
+		csvWriter := csv.NewWriter(w)
+		if err := csvWriter.Write([]string{"ip", "lists", "error"}); err != nil {
+			return err
+		}
+		write = func(result *lookupResponse) error {
+			if err := csvWriter.Write([]string{result.IP, strings.Join(result.Lists, ","), result.Error}); err != nil {
+				return err
+			}
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		search := strings.TrimSpace(scanner.Text())
+		if search == "" || strings.HasPrefix(search, "#") {
+			continue
+		}
+
+		result := newLookupResponse(container, search, searchList)
+		if onlyMiss {
+			if result.Error != "" {
+				log.Printf("⚠️ skipped %s: %s\n", result.IP, result.Error)
+				continue
+			}
+			if result.Found {
+				continue
+			}
+		}
+
+		if err := write(result); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
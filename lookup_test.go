@@ -0,0 +1,201 @@
+package main
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	for name, content := range files {
+		fw, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLookupREPLSourceSignature(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "list.txt"), "1.0.0.0/24\n")
+	writeTestFile(t, filepath.Join(dir, "lists", "cn.txt"), "1.0.1.0/24\n")
+	writeTestFile(t, filepath.Join(dir, "lists", "sub", "au.txt"), "1.0.0.0/24\n")
+	writeTestFile(t, filepath.Join(dir, "config.json"), `{"input": []}`)
+	writeTestZip(t, filepath.Join(dir, "lists.zip"), map[string]string{"cn.txt": "1.0.1.0/24\n"})
+
+	tests := []struct {
+		name    string
+		source  *inputSource
+		changed string // file changed to change the signature
+		files   []string
+		wantErr bool
+	}{
+		{
+			name:    "file",
+			source:  &inputSource{uri: filepath.Join(dir, "list.txt")},
+			changed: "list.txt",
+			files:   []string{"list.txt"},
+		},
+		{
+			name:    "dir",
+			source:  &inputSource{dir: filepath.Join(dir, "lists")},
+			changed: "lists/sub/au.txt",
+			files:   []string{"lists/cn.txt", "lists/sub/au.txt"},
+		},
+		{
+			name:    "archive member",
+			source:  &inputSource{uri: filepath.Join(dir, "lists.zip") + "#cn.txt"},
+			changed: "lists.zip",
+			files:   []string{"lists.zip"},
+		},
+		{
+			name:    "config file",
+			source:  &inputSource{config: filepath.Join(dir, "config.json"), uri: filepath.Join(dir, "list.txt")},
+			changed: "config.json",
+			files:   []string{"config.json"},
+		},
+		{name: "remote", source: &inputSource{uri: "https://example.com/list.txt"}},
+		{name: "remote config", source: &inputSource{config: "HTTPS://example.com/config.json"}},
+		{name: "stdin", source: &inputSource{}},
+		{name: "missing file", source: &inputSource{uri: filepath.Join(dir, "missing.txt")}, wantErr: true},
+		{name: "missing archive", source: &inputSource{uri: filepath.Join(dir, "missing.zip") + "#cn.txt"}, wantErr: true},
+		{name: "missing dir", source: &inputSource{dir: filepath.Join(dir, "missing")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &lookupREPL{source: tt.source}
+			signature, err := r.sourceSignature()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("sourceSignature() = %q, want error", signature)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			files := make([]string, 0)
+			for _, line := range strings.Split(strings.TrimSpace(signature), "\n") {
+				if path, _, found := strings.Cut(line, "|"); found {
+					rel, err := filepath.Rel(dir, path)
+					if err != nil {
+						t.Fatal(err)
+					}
+					files = append(files, filepath.ToSlash(rel))
+				}
+			}
+			if !slices.Equal(files, tt.files) && !(len(files) == 0 && len(tt.files) == 0) {
+				t.Errorf("files in signature = %v, want %v", files, tt.files)
+			}
+
+			if tt.changed == "" {
+				return
+			}
+			path := filepath.Join(dir, tt.changed)
+			future := time.Now().Add(time.Hour)
+			if err := os.Chtimes(path, future, future); err != nil {
+				t.Fatal(err)
+			}
+			if changed, err := r.sourceSignature(); err != nil || changed == signature {
+				t.Errorf("sourceSignature() = %q, error = %v after %s changed, want a different one", changed, err, tt.changed)
+			}
+		})
+	}
+}
+
+func TestLookupREPLReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "list.txt")
+	writeTestFile(t, path, "1.0.0.0/24\n")
+
+	r, err := newLookupREPL(&inputSource{format: "text", name: defaultListName, uri: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSignature := r.signature
+	if oldSignature == "" {
+		t.Fatal("signature of local file is empty")
+	}
+
+	lookup := func(search string) []string {
+		t.Helper()
+		lists, _, err := (*r.container.Load()).Lookup(search)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return lists
+	}
+
+	tests := []struct {
+		content   string
+		lists     []string // lists of 2.0.0.1 after reload
+		reloaded  bool
+		oldLookup []string // lists of 1.0.0.1 after reload
+	}{
+		{content: "1.0.0.0/24\n2.0.0.0/24\n", lists: []string{"TRUE"}, reloaded: true, oldLookup: []string{"TRUE"}},
+		{content: "2.0.0.0/24\n", lists: []string{"TRUE"}, reloaded: true, oldLookup: []string{}},
+		// Invalid data keeps the old container
+		{content: "invalid\n", lists: []string{"TRUE"}, reloaded: false, oldLookup: []string{}},
+	}
+
+	for _, tt := range tests {
+		writeTestFile(t, path, tt.content)
+		future := time.Now().Add(time.Hour)
+		if err := os.Chtimes(path, future, future); err != nil {
+			t.Fatal(err)
+		}
+		signature := r.signature
+
+		r.reload()
+
+		if got := lookup("2.0.0.1"); !slices.Equal(got, tt.lists) {
+			t.Errorf("lookup 2.0.0.1 after reloading %q = %v, want %v", tt.content, got, tt.lists)
+		}
+		if got := lookup("1.0.0.1"); !slices.Equal(got, tt.oldLookup) && !(len(got) == 0 && len(tt.oldLookup) == 0) {
+			t.Errorf("lookup 1.0.0.1 after reloading %q = %v, want %v", tt.content, got, tt.oldLookup)
+		}
+		if reloaded := r.signature != signature; reloaded != tt.reloaded {
+			t.Errorf("signature updated = %v after reloading %q, want %v", reloaded, tt.content, tt.reloaded)
+		}
+	}
+
+	// The reloaded container is frozen for fast lookups
+	entry := lib.NewEntry("other")
+	if err := entry.AddPrefix("3.0.0.0/24"); err != nil {
+		t.Fatal(err)
+	}
+	if err := (*r.container.Load()).Add(entry); err != lib.ErrContainerFrozen {
+		t.Errorf("Add() error = %v, want %v", err, lib.ErrContainerFrozen)
+	}
+}
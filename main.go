@@ -2,16 +2,65 @@ package main
 
 import (
 	"log"
+	"strings"
 
+	"github.com/Loyalsoldier/geoip/lib"
 	"github.com/spf13/cobra"
 )
 
+func init() {
+	rootCmd.PersistentFlags().String("cachedir", "", "Directory to cache remote files in, unchanged files will not be downloaded again")
+	rootCmd.PersistentFlags().Bool("offline", false, "Serve remote files from cache directory only, without network access (requires \"cachedir\" flag)")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Timeout of fetching each remote file, e.g. 30s (0 means no timeout)")
+	rootCmd.PersistentFlags().Int("retry", 0, "Times to retry fetching remote files on network or server errors")
+	rootCmd.PersistentFlags().String("proxy", "", "Proxy URL to fetch remote files with, e.g. http://127.0.0.1:7890 or socks5://127.0.0.1:1080")
+	rootCmd.PersistentFlags().String("useragent", "", "User agent to fetch remote files with")
+	rootCmd.PersistentFlags().StringArray("header", nil, "Extra header to fetch remote files with, in the form of \"Key: Value\" (can be specified multiple times)")
+	rootCmd.MarkPersistentFlagDirname("cachedir")
+}
+
 var rootCmd = &cobra.Command{
 	Use:   "geoip",
 	Short: "geoip is a convenient tool to merge, convert and lookup IP & CIDR from various formats of geoip data.",
 	CompletionOptions: cobra.CompletionOptions{
 		HiddenDefaultCmd: true,
 	},
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cacheDir, _ := cmd.Flags().GetString("cachedir")
+		offline, _ := cmd.Flags().GetBool("offline")
+		if offline && cacheDir == "" {
+			log.Fatal("flag offline requires flag cachedir to be set")
+		}
+
+		lib.SetCacheDir(cacheDir)
+		lib.SetOffline(offline)
+
+		httpConfig := new(lib.HTTPConfig)
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		httpConfig.Timeout = lib.Duration(timeout)
+		// Only set retry if specified, so that 0 disables retrying set in config file
+		if cmd.Flags().Changed("retry") {
+			retry, _ := cmd.Flags().GetInt("retry")
+			if retry < 0 {
+				log.Fatal("invalid argument retry: ", retry)
+			}
+			httpConfig.Retry = &retry
+		}
+		httpConfig.Proxy, _ = cmd.Flags().GetString("proxy")
+		httpConfig.UserAgent, _ = cmd.Flags().GetString("useragent")
+		headers, _ := cmd.Flags().GetStringArray("header")
+		for _, header := range headers {
+			key, value, found := strings.Cut(header, ":")
+			if !found || strings.TrimSpace(key) == "" {
+				log.Fatalf("invalid header %q, must be in the form of \"Key: Value\"", header)
+			}
+			if httpConfig.Headers == nil {
+				httpConfig.Headers = make(map[string]string)
+			}
+			httpConfig.Headers[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+		lib.SetHTTPConfig(httpConfig)
+	},
 }
 
 func main() {
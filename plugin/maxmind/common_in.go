@@ -14,11 +14,71 @@ var (
 	defaultIPInfoCountryMMDBFile   = filepath.Join("./", "ipinfo", "country.mmdb")
 )
 
+// isSameURI returns whether all files are fetched from the same URI,
+// like files in the same archive, so that one checksum applies to all of them.
+func isSameURI(files ...string) bool {
+	uri := ""
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		switch fetched := lib.TrimArchiveMember(file); {
+		case uri == "":
+			uri = fetched
+		case uri != fetched:
+			return false
+		}
+	}
+	return true
+}
+
+func getDefaultURIForMMDBIn(iType string) string {
+	switch iType {
+	case TypeGeoLite2CountryMMDBIn:
+		return defaultGeoLite2CountryMMDBFile
+
+	case TypeDBIPCountryMMDBIn:
+		return defaultDBIPCountryMMDBFile
+
+	case TypeIPInfoCountryMMDBIn:
+		return defaultIPInfoCountryMMDBFile
+	}
+	return ""
+}
+
+func newGeoLite2CountryMMDBInSchema(iType string) *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: append([]*lib.Arg{
+			{
+				Name:        "uri",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the MMDB file",
+				Default:     getDefaultURIForMMDBIn(iType),
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only countries to read",
+			},
+			lib.ArgOnlyIPType(),
+			lib.ArgHTTP(),
+		}, lib.ArgsChecksum()...),
+		Example: map[string]any{
+			"uri":        getDefaultURIForMMDBIn(iType),
+			"wantedList": []string{"cn", "us"},
+		},
+	}
+}
+
 func newGeoLite2CountryMMDBIn(iType string, iDesc string, action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
 	var tmp struct {
 		URI        string     `json:"uri"`
 		Want       []string   `json:"wantedList"`
 		OnlyIPType lib.IPType `json:"onlyIPType"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
 	}
 
 	if len(data) > 0 {
@@ -28,16 +88,7 @@ func newGeoLite2CountryMMDBIn(iType string, iDesc string, action lib.Action, dat
 	}
 
 	if tmp.URI == "" {
-		switch iType {
-		case TypeGeoLite2CountryMMDBIn:
-			tmp.URI = defaultGeoLite2CountryMMDBFile
-
-		case TypeDBIPCountryMMDBIn:
-			tmp.URI = defaultDBIPCountryMMDBFile
-
-		case TypeIPInfoCountryMMDBIn:
-			tmp.URI = defaultIPInfoCountryMMDBFile
-		}
+		tmp.URI = getDefaultURIForMMDBIn(iType)
 	}
 
 	// Filter want list
@@ -55,5 +106,8 @@ func newGeoLite2CountryMMDBIn(iType string, iDesc string, action lib.Action, dat
 		URI:         tmp.URI,
 		Want:        wantList,
 		OnlyIPType:  tmp.OnlyIPType,
+
+		HTTP:     tmp.HTTP,
+		Checksum: tmp.Checksum,
 	}, nil
 }
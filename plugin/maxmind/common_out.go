@@ -3,7 +3,6 @@ package maxmind
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 	"path/filepath"
 	"strings"
 
@@ -100,6 +99,62 @@ func (d dbipCountry) HasData() bool {
 	return d != zeroDBIPCountry
 }
 
+func getDefaultOutputDirForMMDBOut(iType string) string {
+	switch iType {
+	case TypeGeoLite2CountryMMDBOut:
+		return defaultMaxmindOutputDir
+
+	case TypeDBIPCountryMMDBOut:
+		return defaultDBIPOutputDir
+
+	case TypeIPInfoCountryMMDBOut:
+		return defaultIPInfoOutputDir
+	}
+	return ""
+}
+
+func newGeoLite2CountryMMDBOutSchema(iType string) *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "outputName",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the output file",
+				Default:     defaultGeoLite2CountryMMDBOutputName,
+			},
+			{
+				Name:        "outputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory to write the output file to",
+				Default:     getDefaultOutputDirForMMDBOut(iType),
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only lists to output, written last in order",
+			},
+			{
+				Name:        "overwriteList",
+				Type:        lib.ArgTypeStringList,
+				Description: "Lists to write last in order, overwriting data of other lists",
+			},
+			lib.ArgExcludedList(),
+			lib.ArgOnlyIPType(),
+			{
+				Name:        "sourceMMDBURI",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the official MMDB file to complete extra info of countries",
+			},
+			lib.ArgHTTP(),
+		},
+		Example: map[string]any{
+			"outputName":    defaultGeoLite2CountryMMDBOutputName,
+			"overwriteList": []string{"cn", "private"},
+		},
+	}
+}
+
 func newGeoLite2CountryMMDBOut(iType string, iDesc string, action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
 	var tmp struct {
 		OutputName string     `json:"outputName"`
@@ -109,7 +164,8 @@ func newGeoLite2CountryMMDBOut(iType string, iDesc string, action lib.Action, da
 		Exclude    []string   `json:"excludedList"`
 		OnlyIPType lib.IPType `json:"onlyIPType"`
 
-		SourceMMDBURI string `json:"sourceMMDBURI"`
+		SourceMMDBURI string          `json:"sourceMMDBURI"`
+		HTTP          *lib.HTTPConfig `json:"http"`
 	}
 
 	if len(data) > 0 {
@@ -123,16 +179,7 @@ func newGeoLite2CountryMMDBOut(iType string, iDesc string, action lib.Action, da
 	}
 
 	if tmp.OutputDir == "" {
-		switch iType {
-		case TypeGeoLite2CountryMMDBOut:
-			tmp.OutputDir = defaultMaxmindOutputDir
-
-		case TypeDBIPCountryMMDBOut:
-			tmp.OutputDir = defaultDBIPOutputDir
-
-		case TypeIPInfoCountryMMDBOut:
-			tmp.OutputDir = defaultIPInfoOutputDir
-		}
+		tmp.OutputDir = getDefaultOutputDirForMMDBOut(iType)
 	}
 
 	return &GeoLite2CountryMMDBOut{
@@ -147,6 +194,7 @@ func newGeoLite2CountryMMDBOut(iType string, iDesc string, action lib.Action, da
 		OnlyIPType:  tmp.OnlyIPType,
 
 		SourceMMDBURI: tmp.SourceMMDBURI,
+		HTTP:          tmp.HTTP,
 	}, nil
 }
 
@@ -155,14 +203,7 @@ func (g *GeoLite2CountryMMDBOut) GetExtraInfo() (map[string]any, error) {
 		return nil, nil
 	}
 
-	var content []byte
-	var err error
-	switch {
-	case strings.HasPrefix(strings.ToLower(g.SourceMMDBURI), "http://"), strings.HasPrefix(strings.ToLower(g.SourceMMDBURI), "https://"):
-		content, err = lib.GetRemoteURLContent(g.SourceMMDBURI)
-	default:
-		content, err = os.ReadFile(g.SourceMMDBURI)
-	}
+	content, err := lib.ReadURI(g.SourceMMDBURI, nil, g.HTTP)
 	if err != nil {
 		return nil, err
 	}
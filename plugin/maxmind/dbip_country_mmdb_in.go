@@ -23,4 +23,5 @@ func init() {
 	lib.RegisterInputConverter(TypeDBIPCountryMMDBIn, &GeoLite2CountryMMDBIn{
 		Description: DescDBIPCountryMMDBIn,
 	})
+	lib.RegisterInputConverterSchema(TypeDBIPCountryMMDBIn, newGeoLite2CountryMMDBInSchema(TypeDBIPCountryMMDBIn))
 }
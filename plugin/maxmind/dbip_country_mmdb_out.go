@@ -23,4 +23,5 @@ func init() {
 	lib.RegisterOutputConverter(TypeDBIPCountryMMDBOut, &GeoLite2CountryMMDBOut{
 		Description: DescDBIPCountryMMDBOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeDBIPCountryMMDBOut, newGeoLite2CountryMMDBOutSchema(TypeDBIPCountryMMDBOut))
 }
@@ -23,4 +23,5 @@ func init() {
 	lib.RegisterInputConverter(TypeIPInfoCountryMMDBIn, &GeoLite2CountryMMDBIn{
 		Description: DescIPInfoCountryMMDBIn,
 	})
+	lib.RegisterInputConverterSchema(TypeIPInfoCountryMMDBIn, newGeoLite2CountryMMDBInSchema(TypeIPInfoCountryMMDBIn))
 }
@@ -23,4 +23,5 @@ func init() {
 	lib.RegisterOutputConverter(TypeIPInfoCountryMMDBOut, &GeoLite2CountryMMDBOut{
 		Description: DescIPInfoCountryMMDBOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeIPInfoCountryMMDBOut, newGeoLite2CountryMMDBOutSchema(TypeIPInfoCountryMMDBOut))
 }
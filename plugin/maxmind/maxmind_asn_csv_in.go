@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -29,6 +29,50 @@ func init() {
 	lib.RegisterInputConverter(TypeGeoLite2ASNCSVIn, &GeoLite2ASNCSVIn{
 		Description: DescGeoLite2ASNCSVIn,
 	})
+	lib.RegisterInputConverterSchema(TypeGeoLite2ASNCSVIn, newGeoLite2ASNCSVInSchema())
+}
+
+func newGeoLite2ASNCSVInSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: append([]*lib.Arg{
+			{
+				Name:        "ipv4",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the IPv4 blocks CSV file",
+				Default:     defaultGeoLite2ASNCSVIPv4File,
+			},
+			{
+				Name:        "ipv6",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the IPv6 blocks CSV file",
+				Default:     defaultGeoLite2ASNCSVIPv6File,
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringListOrMap,
+				Description: "The only ASNs to read, or list names mapped to their ASNs",
+			},
+			lib.ArgOnlyIPType(),
+			lib.ArgHTTP(),
+			{
+				Name:        "ipv4SHA256",
+				Type:        lib.ArgTypeString,
+				Description: "The expected SHA-256 checksum of the IPv4 blocks CSV file",
+			},
+			{
+				Name:        "ipv6SHA256",
+				Type:        lib.ArgTypeString,
+				Description: "The expected SHA-256 checksum of the IPv6 blocks CSV file",
+			},
+		}, lib.ArgsChecksum()...),
+		Example: map[string]any{
+			"wantedList": map[string][]string{
+				"facebook": {"AS63293", "AS54115", "AS32934"},
+				"fastly":   {"AS54113", "AS394192"},
+			},
+		},
+	}
 }
 
 func newGeoLite2ASNCSVIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -37,6 +81,11 @@ func newGeoLite2ASNCSVIn(action lib.Action, data json.RawMessage) (lib.InputConv
 		IPv6File   string                 `json:"ipv6"`
 		Want       lib.WantedListExtended `json:"wantedList"`
 		OnlyIPType lib.IPType             `json:"onlyIPType"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
+		IPv4SHA256 lib.SHA256 `json:"ipv4SHA256"`
+		IPv6SHA256 lib.SHA256 `json:"ipv6SHA256"`
 	}
 
 	if len(data) > 0 {
@@ -52,6 +101,11 @@ func newGeoLite2ASNCSVIn(action lib.Action, data json.RawMessage) (lib.InputConv
 		tmp.IPv6File = defaultGeoLite2ASNCSVIPv6File
 	}
 
+	sameURI := isSameURI(tmp.IPv4File, tmp.IPv6File)
+	if tmp.SHA256 != "" && !sameURI {
+		return nil, fmt.Errorf("❌ [type %s | action %s] sha256 can only be used when all files are in the same archive, use ipv4SHA256 and ipv6SHA256 instead", TypeGeoLite2ASNCSVIn, action)
+	}
+
 	// Filter want list
 	wantList := make(map[string][]string) // map[asn][]listname or map[asn][]asn
 
@@ -93,6 +147,11 @@ func newGeoLite2ASNCSVIn(action lib.Action, data json.RawMessage) (lib.InputConv
 		IPv6File:    tmp.IPv6File,
 		Want:        wantList,
 		OnlyIPType:  tmp.OnlyIPType,
+
+		HTTP:       tmp.HTTP,
+		Checksum:   tmp.Checksum.ForFiles(sameURI),
+		IPv4SHA256: tmp.IPv4SHA256,
+		IPv6SHA256: tmp.IPv6SHA256,
 	}, nil
 }
 
@@ -104,6 +163,13 @@ type GeoLite2ASNCSVIn struct {
 	IPv6File    string
 	Want        map[string][]string
 	OnlyIPType  lib.IPType
+
+	HTTP       *lib.HTTPConfig
+	Checksum   lib.Checksum
+	IPv4SHA256 lib.SHA256
+	IPv6SHA256 lib.SHA256
+
+	prefetched map[string]*lib.Entry
 }
 
 func (g *GeoLite2ASNCSVIn) GetType() string {
@@ -118,23 +184,45 @@ func (g *GeoLite2ASNCSVIn) GetDescription() string {
 	return g.Description
 }
 
-func (g *GeoLite2ASNCSVIn) Input(container lib.Container) (lib.Container, error) {
-	entries := make(map[string]*lib.Entry)
-
-	if g.IPv4File != "" {
-		if err := g.process(g.IPv4File, entries); err != nil {
-			return nil, err
+func (g *GeoLite2ASNCSVIn) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(nil, nil, g.OnlyIPType)
+	args["ipv4"] = g.IPv4File
+	args["ipv6"] = g.IPv6File
+
+	// Show lists with their ASNs, as written in config file
+	if len(g.Want) > 0 {
+		want := make(map[string][]string)
+		for asn, lists := range g.Want {
+			for _, list := range lists {
+				want[list] = append(want[list], asn)
+			}
 		}
+		for _, asns := range want {
+			slices.Sort(asns)
+		}
+		args["wantedList"] = want
 	}
+	return args
+}
 
-	if g.IPv6File != "" {
-		if err := g.process(g.IPv6File, entries); err != nil {
-			return nil, err
-		}
+func (g *GeoLite2ASNCSVIn) Prefetch() error {
+	entries, err := g.loadEntries()
+	if err != nil {
+		return err
 	}
 
-	if len(entries) == 0 {
-		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	g.prefetched = entries
+	return nil
+}
+
+func (g *GeoLite2ASNCSVIn) Input(container lib.Container) (lib.Container, error) {
+	entries := g.prefetched
+	g.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = g.loadEntries(); err != nil {
+			return nil, err
+		}
 	}
 
 	ignoreIPType := lib.GetIgnoreIPType(g.OnlyIPType)
@@ -157,20 +245,34 @@ func (g *GeoLite2ASNCSVIn) Input(container lib.Container) (lib.Container, error)
 	return container, nil
 }
 
-func (g *GeoLite2ASNCSVIn) process(file string, entries map[string]*lib.Entry) error {
-	if entries == nil {
-		entries = make(map[string]*lib.Entry)
+func (g *GeoLite2ASNCSVIn) loadEntries() (map[string]*lib.Entry, error) {
+	entries := make(map[string]*lib.Entry)
+
+	if g.IPv4File != "" {
+		if err := g.process(g.IPv4File, g.Checksum.WithSHA256(g.IPv4SHA256), entries); err != nil {
+			return nil, err
+		}
 	}
 
-	var f io.ReadCloser
-	var err error
-	switch {
-	case strings.HasPrefix(strings.ToLower(file), "http://"), strings.HasPrefix(strings.ToLower(file), "https://"):
-		f, err = lib.GetRemoteURLReader(file)
-	default:
-		f, err = os.Open(file)
+	if g.IPv6File != "" {
+		if err := g.process(g.IPv6File, g.Checksum.WithSHA256(g.IPv6SHA256), entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	}
+
+	return entries, nil
+}
+
+func (g *GeoLite2ASNCSVIn) process(file string, checksum *lib.Checksum, entries map[string]*lib.Entry) error {
+	if entries == nil {
+		entries = make(map[string]*lib.Entry)
 	}
 
+	f, err := lib.OpenURI(file, checksum, g.HTTP)
 	if err != nil {
 		return err
 	}
@@ -5,8 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
+	"maps"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -30,6 +31,58 @@ func init() {
 	lib.RegisterInputConverter(TypeGeoLite2CountryCSVIn, &GeoLite2CountryCSVIn{
 		Description: DescGeoLite2CountryCSVIn,
 	})
+	lib.RegisterInputConverterSchema(TypeGeoLite2CountryCSVIn, newGeoLite2CountryCSVInSchema())
+}
+
+func newGeoLite2CountryCSVInSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: append([]*lib.Arg{
+			{
+				Name:        "country",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the country locations CSV file",
+				Default:     defaultGeoLite2CountryCodeFile,
+			},
+			{
+				Name:        "ipv4",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the IPv4 blocks CSV file",
+				Default:     defaultGeoLite2CountryIPv4File,
+			},
+			{
+				Name:        "ipv6",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the IPv6 blocks CSV file",
+				Default:     defaultGeoLite2CountryIPv6File,
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only countries to read",
+			},
+			lib.ArgOnlyIPType(),
+			lib.ArgHTTP(),
+			{
+				Name:        "countrySHA256",
+				Type:        lib.ArgTypeString,
+				Description: "The expected SHA-256 checksum of the country locations CSV file",
+			},
+			{
+				Name:        "ipv4SHA256",
+				Type:        lib.ArgTypeString,
+				Description: "The expected SHA-256 checksum of the IPv4 blocks CSV file",
+			},
+			{
+				Name:        "ipv6SHA256",
+				Type:        lib.ArgTypeString,
+				Description: "The expected SHA-256 checksum of the IPv6 blocks CSV file",
+			},
+		}, lib.ArgsChecksum()...),
+		Example: map[string]any{
+			"wantedList": []string{"cn", "us"},
+		},
+	}
 }
 
 func newGeoLite2CountryCSVIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -39,6 +92,12 @@ func newGeoLite2CountryCSVIn(action lib.Action, data json.RawMessage) (lib.Input
 		IPv6File        string     `json:"ipv6"`
 		Want            []string   `json:"wantedList"`
 		OnlyIPType      lib.IPType `json:"onlyIPType"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
+		CountrySHA256 lib.SHA256 `json:"countrySHA256"`
+		IPv4SHA256    lib.SHA256 `json:"ipv4SHA256"`
+		IPv6SHA256    lib.SHA256 `json:"ipv6SHA256"`
 	}
 
 	if len(data) > 0 {
@@ -58,6 +117,11 @@ func newGeoLite2CountryCSVIn(action lib.Action, data json.RawMessage) (lib.Input
 		tmp.IPv6File = defaultGeoLite2CountryIPv6File
 	}
 
+	sameURI := isSameURI(tmp.CountryCodeFile, tmp.IPv4File, tmp.IPv6File)
+	if tmp.SHA256 != "" && !sameURI {
+		return nil, fmt.Errorf("❌ [type %s | action %s] sha256 can only be used when all files are in the same archive, use countrySHA256, ipv4SHA256 and ipv6SHA256 instead", TypeGeoLite2CountryCSVIn, action)
+	}
+
 	// Filter want list
 	wantList := make(map[string]bool)
 	for _, want := range tmp.Want {
@@ -75,6 +139,12 @@ func newGeoLite2CountryCSVIn(action lib.Action, data json.RawMessage) (lib.Input
 		IPv6File:        tmp.IPv6File,
 		Want:            wantList,
 		OnlyIPType:      tmp.OnlyIPType,
+
+		HTTP:          tmp.HTTP,
+		Checksum:      tmp.Checksum.ForFiles(sameURI),
+		CountrySHA256: tmp.CountrySHA256,
+		IPv4SHA256:    tmp.IPv4SHA256,
+		IPv6SHA256:    tmp.IPv6SHA256,
 	}, nil
 }
 
@@ -87,6 +157,14 @@ type GeoLite2CountryCSVIn struct {
 	IPv6File        string
 	Want            map[string]bool
 	OnlyIPType      lib.IPType
+
+	HTTP          *lib.HTTPConfig
+	Checksum      lib.Checksum
+	CountrySHA256 lib.SHA256
+	IPv4SHA256    lib.SHA256
+	IPv6SHA256    lib.SHA256
+
+	prefetched map[string]*lib.Entry
 }
 
 func (g *GeoLite2CountryCSVIn) GetType() string {
@@ -101,30 +179,34 @@ func (g *GeoLite2CountryCSVIn) GetDescription() string {
 	return g.Description
 }
 
-func (g *GeoLite2CountryCSVIn) Input(container lib.Container) (lib.Container, error) {
-	ccMap, err := g.getCountryCode()
+func (g *GeoLite2CountryCSVIn) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(slices.Collect(maps.Keys(g.Want)), nil, g.OnlyIPType)
+	args["country"] = g.CountryCodeFile
+	args["ipv4"] = g.IPv4File
+	args["ipv6"] = g.IPv6File
+	return args
+}
+
+func (g *GeoLite2CountryCSVIn) Prefetch() error {
+	entries, err := g.loadEntries()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	entries := make(map[string]*lib.Entry, len(ccMap))
-
-	if g.IPv4File != "" {
-		if err := g.process(g.IPv4File, ccMap, entries); err != nil {
-			return nil, err
-		}
-	}
+	g.prefetched = entries
+	return nil
+}
 
-	if g.IPv6File != "" {
-		if err := g.process(g.IPv6File, ccMap, entries); err != nil {
+func (g *GeoLite2CountryCSVIn) Input(container lib.Container) (lib.Container, error) {
+	entries := g.prefetched
+	g.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = g.loadEntries(); err != nil {
 			return nil, err
 		}
 	}
 
-	if len(entries) == 0 {
-		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
-	}
-
 	ignoreIPType := lib.GetIgnoreIPType(g.OnlyIPType)
 
 	for _, entry := range entries {
@@ -145,16 +227,35 @@ func (g *GeoLite2CountryCSVIn) Input(container lib.Container) (lib.Container, er
 	return container, nil
 }
 
-func (g *GeoLite2CountryCSVIn) getCountryCode() (map[string]string, error) {
-	var f io.ReadCloser
-	var err error
-	switch {
-	case strings.HasPrefix(strings.ToLower(g.CountryCodeFile), "http://"), strings.HasPrefix(strings.ToLower(g.CountryCodeFile), "https://"):
-		f, err = lib.GetRemoteURLReader(g.CountryCodeFile)
-	default:
-		f, err = os.Open(g.CountryCodeFile)
+func (g *GeoLite2CountryCSVIn) loadEntries() (map[string]*lib.Entry, error) {
+	ccMap, err := g.getCountryCode()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*lib.Entry, len(ccMap))
+
+	if g.IPv4File != "" {
+		if err := g.process(g.IPv4File, g.Checksum.WithSHA256(g.IPv4SHA256), ccMap, entries); err != nil {
+			return nil, err
+		}
 	}
 
+	if g.IPv6File != "" {
+		if err := g.process(g.IPv6File, g.Checksum.WithSHA256(g.IPv6SHA256), ccMap, entries); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	}
+
+	return entries, nil
+}
+
+func (g *GeoLite2CountryCSVIn) getCountryCode() (map[string]string, error) {
+	f, err := lib.OpenURI(g.CountryCodeFile, g.Checksum.WithSHA256(g.CountrySHA256), g.HTTP)
 	if err != nil {
 		return nil, err
 	}
@@ -192,7 +293,7 @@ func (g *GeoLite2CountryCSVIn) getCountryCode() (map[string]string, error) {
 	return ccMap, nil
 }
 
-func (g *GeoLite2CountryCSVIn) process(file string, ccMap map[string]string, entries map[string]*lib.Entry) error {
+func (g *GeoLite2CountryCSVIn) process(file string, checksum *lib.Checksum, ccMap map[string]string, entries map[string]*lib.Entry) error {
 	if len(ccMap) == 0 {
 		return fmt.Errorf("❌ [type %s | action %s] invalid country code data", g.Type, g.Action)
 	}
@@ -200,15 +301,7 @@ func (g *GeoLite2CountryCSVIn) process(file string, ccMap map[string]string, ent
 		entries = make(map[string]*lib.Entry, len(ccMap))
 	}
 
-	var f io.ReadCloser
-	var err error
-	switch {
-	case strings.HasPrefix(strings.ToLower(file), "http://"), strings.HasPrefix(strings.ToLower(file), "https://"):
-		f, err = lib.GetRemoteURLReader(file)
-	default:
-		f, err = os.Open(file)
-	}
-
+	f, err := lib.OpenURI(file, checksum, g.HTTP)
 	if err != nil {
 		return err
 	}
@@ -3,7 +3,8 @@ package maxmind
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -23,6 +24,7 @@ func init() {
 	lib.RegisterInputConverter(TypeGeoLite2CountryMMDBIn, &GeoLite2CountryMMDBIn{
 		Description: DescGeoLite2CountryMMDBIn,
 	})
+	lib.RegisterInputConverterSchema(TypeGeoLite2CountryMMDBIn, newGeoLite2CountryMMDBInSchema(TypeGeoLite2CountryMMDBIn))
 }
 
 type GeoLite2CountryMMDBIn struct {
@@ -32,6 +34,11 @@ type GeoLite2CountryMMDBIn struct {
 	URI         string
 	Want        map[string]bool
 	OnlyIPType  lib.IPType
+
+	HTTP     *lib.HTTPConfig
+	Checksum lib.Checksum
+
+	prefetched map[string]*lib.Entry
 }
 
 func (g *GeoLite2CountryMMDBIn) GetType() string {
@@ -46,27 +53,30 @@ func (g *GeoLite2CountryMMDBIn) GetDescription() string {
 	return g.Description
 }
 
-func (g *GeoLite2CountryMMDBIn) Input(container lib.Container) (lib.Container, error) {
-	var content []byte
-	var err error
-	switch {
-	case strings.HasPrefix(strings.ToLower(g.URI), "http://"), strings.HasPrefix(strings.ToLower(g.URI), "https://"):
-		content, err = lib.GetRemoteURLContent(g.URI)
-	default:
-		content, err = os.ReadFile(g.URI)
-	}
-	if err != nil {
-		return nil, err
-	}
+func (g *GeoLite2CountryMMDBIn) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(slices.Collect(maps.Keys(g.Want)), nil, g.OnlyIPType)
+	args["uri"] = g.URI
+	return args
+}
 
-	entries := make(map[string]*lib.Entry, 300)
-	err = g.generateEntries(content, entries)
+func (g *GeoLite2CountryMMDBIn) Prefetch() error {
+	entries, err := g.loadEntries()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if len(entries) == 0 {
-		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	g.prefetched = entries
+	return nil
+}
+
+func (g *GeoLite2CountryMMDBIn) Input(container lib.Container) (lib.Container, error) {
+	entries := g.prefetched
+	g.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = g.loadEntries(); err != nil {
+			return nil, err
+		}
 	}
 
 	ignoreIPType := lib.GetIgnoreIPType(g.OnlyIPType)
@@ -89,6 +99,25 @@ func (g *GeoLite2CountryMMDBIn) Input(container lib.Container) (lib.Container, e
 	return container, nil
 }
 
+func (g *GeoLite2CountryMMDBIn) loadEntries() (map[string]*lib.Entry, error) {
+	content, err := lib.ReadURI(g.URI, &g.Checksum, g.HTTP)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[string]*lib.Entry, 300)
+	err = g.generateEntries(content, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	}
+
+	return entries, nil
+}
+
 func (g *GeoLite2CountryMMDBIn) generateEntries(content []byte, entries map[string]*lib.Entry) error {
 	db, err := maxminddb.OpenBytes(content)
 	if err != nil {
@@ -27,6 +27,7 @@ func init() {
 	lib.RegisterOutputConverter(TypeGeoLite2CountryMMDBOut, &GeoLite2CountryMMDBOut{
 		Description: DescGeoLite2CountryMMDBOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeGeoLite2CountryMMDBOut, newGeoLite2CountryMMDBOutSchema(TypeGeoLite2CountryMMDBOut))
 }
 
 type GeoLite2CountryMMDBOut struct {
@@ -41,6 +42,7 @@ type GeoLite2CountryMMDBOut struct {
 	OnlyIPType  lib.IPType
 
 	SourceMMDBURI string
+	HTTP          *lib.HTTPConfig
 }
 
 func (g *GeoLite2CountryMMDBOut) GetType() string {
@@ -55,6 +57,13 @@ func (g *GeoLite2CountryMMDBOut) GetDescription() string {
 	return g.Description
 }
 
+func (g *GeoLite2CountryMMDBOut) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(g.Want, g.Exclude, g.OnlyIPType)
+	args["outputName"] = g.OutputName
+	args["outputDir"] = g.OutputDir
+	return args
+}
+
 func (g *GeoLite2CountryMMDBOut) Output(container lib.Container) error {
 	dbName := ""
 	dbDesc := ""
@@ -371,6 +380,10 @@ func (g *GeoLite2CountryMMDBOut) marshalData(writer *mmdbwriter.Tree, entry *lib
 	return nil
 }
 
+func (g *GeoLite2CountryMMDBOut) OutputFiles() []string {
+	return []string{filepath.Join(g.OutputDir, g.OutputName)}
+}
+
 func (g *GeoLite2CountryMMDBOut) writeFile(filename string, writer *mmdbwriter.Tree) error {
 	if err := os.MkdirAll(g.OutputDir, 0755); err != nil {
 		return err
@@ -6,11 +6,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"maps"
 	"net/netip"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -32,6 +33,41 @@ func init() {
 	lib.RegisterInputConverter(TypeMRSIn, &MRSIn{
 		Description: DescMRSIn,
 	})
+	lib.RegisterInputConverterSchema(TypeMRSIn, newMRSInSchema())
+}
+
+func newMRSInSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: append([]*lib.Arg{
+			{
+				Name:        "name",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the list, used with uri",
+			},
+			{
+				Name:        "uri",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the MRS file, used with name",
+			},
+			{
+				Name:        "inputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory of MRS files to read, with file names as list names",
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only files to read in inputDir",
+			},
+			lib.ArgOnlyIPType(),
+			lib.ArgHTTP(),
+		}, lib.ArgsChecksum()...),
+		Example: map[string]any{
+			"name": "cn",
+			"uri":  "./cn.mrs",
+		},
+	}
 }
 
 func newMRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -41,6 +77,9 @@ func newMRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, erro
 		InputDir   string     `json:"inputDir"`
 		Want       []string   `json:"wantedList"`
 		OnlyIPType lib.IPType `json:"onlyIPType"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
 	}
 
 	if len(data) > 0 {
@@ -57,6 +96,15 @@ func newMRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, erro
 		return nil, fmt.Errorf("❌ [type %s | action %s] name & uri must be specified together", TypeMRSIn, action)
 	}
 
+	// Files in inputDir can only be verified by a checksum file with their filenames
+	checksum := tmp.Checksum
+	if tmp.InputDir != "" {
+		if tmp.SHA256 != "" {
+			return nil, fmt.Errorf("❌ [type %s | action %s] sha256 cannot be used with inputDir, use sha256URI instead", TypeMRSIn, action)
+		}
+		checksum = checksum.ForFiles(false)
+	}
+
 	// Filter want list
 	wantList := make(map[string]bool)
 	for _, want := range tmp.Want {
@@ -74,6 +122,9 @@ func newMRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, erro
 		InputDir:    tmp.InputDir,
 		Want:        wantList,
 		OnlyIPType:  tmp.OnlyIPType,
+
+		HTTP:     tmp.HTTP,
+		Checksum: checksum,
 	}, nil
 }
 
@@ -86,6 +137,11 @@ type MRSIn struct {
 	InputDir    string
 	Want        map[string]bool
 	OnlyIPType  lib.IPType
+
+	HTTP     *lib.HTTPConfig
+	Checksum lib.Checksum
+
+	prefetched map[string]*lib.Entry
 }
 
 func (m *MRSIn) GetType() string {
@@ -100,7 +156,51 @@ func (m *MRSIn) GetDescription() string {
 	return m.Description
 }
 
+func (m *MRSIn) ResolvedArgs() map[string]any {
+	return lib.ListArgs(slices.Collect(maps.Keys(m.Want)), nil, m.OnlyIPType)
+}
+
+func (m *MRSIn) Prefetch() error {
+	entries, err := m.loadEntries()
+	if err != nil {
+		return err
+	}
+
+	m.prefetched = entries
+	return nil
+}
+
 func (m *MRSIn) Input(container lib.Container) (lib.Container, error) {
+	entries := m.prefetched
+	m.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = m.loadEntries(); err != nil {
+			return nil, err
+		}
+	}
+
+	ignoreIPType := lib.GetIgnoreIPType(m.OnlyIPType)
+
+	for _, entry := range entries {
+		switch m.Action {
+		case lib.ActionAdd:
+			if err := container.Add(entry, ignoreIPType); err != nil {
+				return nil, err
+			}
+		case lib.ActionRemove:
+			if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, lib.ErrUnknownAction
+		}
+	}
+
+	return container, nil
+}
+
+func (m *MRSIn) loadEntries() (map[string]*lib.Entry, error) {
 	entries := make(map[string]*lib.Entry)
 	var err error
 
@@ -126,24 +226,7 @@ func (m *MRSIn) Input(container lib.Container) (lib.Container, error) {
 		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", m.Type, m.Action)
 	}
 
-	ignoreIPType := lib.GetIgnoreIPType(m.OnlyIPType)
-
-	for _, entry := range entries {
-		switch m.Action {
-		case lib.ActionAdd:
-			if err := container.Add(entry, ignoreIPType); err != nil {
-				return nil, err
-			}
-		case lib.ActionRemove:
-			if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
-				return nil, err
-			}
-		default:
-			return nil, lib.ErrUnknownAction
-		}
-	}
-
-	return container, nil
+	return entries, nil
 }
 
 func (m *MRSIn) walkDir(dir string, entries map[string]*lib.Entry) error {
@@ -190,7 +273,7 @@ func (m *MRSIn) walkLocalFile(path, name string, entries map[string]*lib.Entry)
 		return fmt.Errorf("❌ [type %s | action %s] found duplicated list %s", m.Type, m.Action, entryName)
 	}
 
-	file, err := os.Open(path)
+	file, err := lib.OpenVerifiedLocalFile(path, &m.Checksum, m.HTTP)
 	if err != nil {
 		return err
 	}
@@ -204,17 +287,13 @@ func (m *MRSIn) walkLocalFile(path, name string, entries map[string]*lib.Entry)
 }
 
 func (m *MRSIn) walkRemoteFile(url, name string, entries map[string]*lib.Entry) error {
-	resp, err := http.Get(url)
+	body, err := lib.GetVerifiedRemoteURLReader(url, &m.Checksum, m.HTTP)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("❌ [type %s | action %s] failed to get remote file %s, http status code %d", m.Type, m.Action, url, resp.StatusCode)
+		return fmt.Errorf("❌ [type %s | action %s] %v", m.Type, m.Action, err)
 	}
+	defer body.Close()
 
-	if err := m.generateEntries(name, resp.Body, entries); err != nil {
+	if err := m.generateEntries(name, body, entries); err != nil {
 		return err
 	}
 
@@ -32,6 +32,27 @@ func init() {
 	lib.RegisterOutputConverter(TypeMRSOut, &MRSOut{
 		Description: DescMRSOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeMRSOut, newMRSOutSchema())
+}
+
+func newMRSOutSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "outputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory to write MRS files to, one file per list",
+				Default:     defaultOutputDir,
+			},
+			lib.ArgWantedList(),
+			lib.ArgExcludedList(),
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"wantedList": []string{"cn", "private"},
+		},
+	}
 }
 
 func newMRSOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
@@ -85,20 +106,22 @@ func (m *MRSOut) GetDescription() string {
 	return m.Description
 }
 
+func (m *MRSOut) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(m.Want, m.Exclude, m.OnlyIPType)
+	args["outputDir"] = m.OutputDir
+	return args
+}
+
 func (m *MRSOut) Output(container lib.Container) error {
-	for _, name := range m.filterAndSortList(container) {
+	return lib.ForEachConcurrently(m.filterAndSortList(container), func(name string) error {
 		entry, found := container.GetEntry(name)
 		if !found {
 			log.Printf("❌ entry %s not found\n", name)
-			continue
-		}
-
-		if err := m.generate(entry); err != nil {
-			return err
+			return nil
 		}
-	}
 
-	return nil
+		return m.generate(entry)
+	})
 }
 
 func (m *MRSOut) filterAndSortList(container lib.Container) []string {
@@ -155,6 +178,10 @@ func (m *MRSOut) generate(entry *lib.Entry) error {
 	return nil
 }
 
+func (m *MRSOut) OutputFiles() []string {
+	return lib.ListFiles(m.OutputDir, ".mrs", m.Want, m.Exclude)
+}
+
 func (m *MRSOut) writeFile(filename string, ipRanges []netipx.IPRange) error {
 	if err := os.MkdirAll(m.OutputDir, 0755); err != nil {
 		return err
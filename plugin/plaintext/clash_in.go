@@ -26,6 +26,7 @@ func init() {
 	lib.RegisterInputConverter(TypeClashRuleSetClassicalIn, &TextIn{
 		Description: DescClashRuleSetClassicalIn,
 	})
+	lib.RegisterInputConverterSchema(TypeClashRuleSetClassicalIn, newTextInSchema(TypeClashRuleSetClassicalIn))
 
 	lib.RegisterInputConfigCreator(TypeClashRuleSetIPCIDRIn, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
 		return newTextIn(TypeClashRuleSetIPCIDRIn, DescClashRuleSetIPCIDRIn, action, data)
@@ -33,4 +34,5 @@ func init() {
 	lib.RegisterInputConverter(TypeClashRuleSetIPCIDRIn, &TextIn{
 		Description: DescClashRuleSetIPCIDRIn,
 	})
+	lib.RegisterInputConverterSchema(TypeClashRuleSetIPCIDRIn, newTextInSchema(TypeClashRuleSetIPCIDRIn))
 }
@@ -26,6 +26,7 @@ func init() {
 	lib.RegisterOutputConverter(TypeClashRuleSetClassicalOut, &TextOut{
 		Description: DescClashRuleSetClassicalOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeClashRuleSetClassicalOut, newTextOutSchema(TypeClashRuleSetClassicalOut))
 
 	lib.RegisterOutputConfigCreator(TypeClashRuleSetIPCIDROut, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
 		return newTextOut(TypeClashRuleSetIPCIDROut, DescClashRuleSetIPCIDROut, action, data)
@@ -33,4 +34,5 @@ func init() {
 	lib.RegisterOutputConverter(TypeClashRuleSetIPCIDROut, &TextOut{
 		Description: DescClashRuleSetIPCIDROut,
 	})
+	lib.RegisterOutputConverterSchema(TypeClashRuleSetIPCIDROut, newTextOutSchema(TypeClashRuleSetIPCIDROut))
 }
@@ -25,6 +25,11 @@ type TextIn struct {
 	JSONPath             []string
 	RemovePrefixesInLine []string
 	RemoveSuffixesInLine []string
+
+	HTTP     *lib.HTTPConfig
+	Checksum lib.Checksum
+
+	prefetched map[string]*lib.Entry
 }
 
 func (t *TextIn) scanFile(reader io.Reader, entry *lib.Entry) error {
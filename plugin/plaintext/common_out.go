@@ -18,6 +18,57 @@ var (
 	defaultOutputDirForSurgeRuleSetOut          = filepath.Join("./", "output", "surge")
 )
 
+func getDefaultOutputDirForTextOut(iType string) string {
+	switch iType {
+	case TypeTextOut:
+		return defaultOutputDirForTextOut
+	case TypeClashRuleSetClassicalOut:
+		return defaultOutputDirForClashRuleSetClassicalOut
+	case TypeClashRuleSetIPCIDROut:
+		return defaultOutputDirForClashRuleSetIPCIDROut
+	case TypeSurgeRuleSetOut:
+		return defaultOutputDirForSurgeRuleSetOut
+	}
+	return ""
+}
+
+func newTextOutSchema(iType string) *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "outputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory to write files to, one file per list",
+				Default:     getDefaultOutputDirForTextOut(iType),
+			},
+			{
+				Name:        "outputExtension",
+				Type:        lib.ArgTypeString,
+				Description: "Extension of output files",
+				Default:     ".txt",
+			},
+			lib.ArgWantedList(),
+			lib.ArgExcludedList(),
+			lib.ArgOnlyIPType(),
+			{
+				Name:        "addPrefixInLine",
+				Type:        lib.ArgTypeString,
+				Description: "Prefix to add to each line",
+			},
+			{
+				Name:        "addSuffixInLine",
+				Type:        lib.ArgTypeString,
+				Description: "Suffix to add to each line",
+			},
+		},
+		Example: map[string]any{
+			"wantedList": []string{"cn", "private"},
+			"onlyIPType": lib.IPv4,
+		},
+	}
+}
+
 type TextOut struct {
 	Type        string
 	Action      lib.Action
@@ -51,16 +102,7 @@ func newTextOut(iType string, iDesc string, action lib.Action, data json.RawMess
 	}
 
 	if tmp.OutputDir == "" {
-		switch iType {
-		case TypeTextOut:
-			tmp.OutputDir = defaultOutputDirForTextOut
-		case TypeClashRuleSetClassicalOut:
-			tmp.OutputDir = defaultOutputDirForClashRuleSetClassicalOut
-		case TypeClashRuleSetIPCIDROut:
-			tmp.OutputDir = defaultOutputDirForClashRuleSetIPCIDROut
-		case TypeSurgeRuleSetOut:
-			tmp.OutputDir = defaultOutputDirForSurgeRuleSetOut
-		}
+		tmp.OutputDir = getDefaultOutputDirForTextOut(iType)
 	}
 
 	if tmp.OutputExt == "" {
@@ -173,6 +215,10 @@ func (t *TextOut) marshalBytesForSurgeRuleSetOut(buf *bytes.Buffer, entryCidr []
 	return nil
 }
 
+func (t *TextOut) OutputFiles() []string {
+	return lib.ListFiles(t.OutputDir, t.OutputExt, t.Want, t.Exclude)
+}
+
 func (t *TextOut) writeFile(filename string, data []byte) error {
 	if err := os.MkdirAll(t.OutputDir, 0755); err != nil {
 		return err
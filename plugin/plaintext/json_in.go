@@ -19,4 +19,5 @@ func init() {
 	lib.RegisterInputConverter(TypeJSONIn, &TextIn{
 		Description: DescJSONIn,
 	})
+	lib.RegisterInputConverterSchema(TypeJSONIn, newTextInSchema(TypeJSONIn))
 }
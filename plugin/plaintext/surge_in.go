@@ -23,4 +23,5 @@ func init() {
 	lib.RegisterInputConverter(TypeSurgeRuleSetIn, &TextIn{
 		Description: DescSurgeRuleSetIn,
 	})
+	lib.RegisterInputConverterSchema(TypeSurgeRuleSetIn, newTextInSchema(TypeSurgeRuleSetIn))
 }
@@ -23,4 +23,5 @@ func init() {
 	lib.RegisterOutputConverter(TypeSurgeRuleSetOut, &TextOut{
 		Description: DescSurgeRuleSetOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeSurgeRuleSetOut, newTextOutSchema(TypeSurgeRuleSetOut))
 }
@@ -3,10 +3,11 @@ package plaintext
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -24,6 +25,84 @@ func init() {
 	lib.RegisterInputConverter(TypeTextIn, &TextIn{
 		Description: DescTextIn,
 	})
+	lib.RegisterInputConverterSchema(TypeTextIn, newTextInSchema(TypeTextIn))
+}
+
+func newTextInSchema(iType string) *lib.ConverterSchema {
+	args := []*lib.Arg{
+		{
+			Name:        "name",
+			Type:        lib.ArgTypeString,
+			Description: "Name of the list, used with uri",
+		},
+		{
+			Name:        "uri",
+			Type:        lib.ArgTypeString,
+			Description: "Path or URL of the file",
+		},
+	}
+	if iType == TypeTextIn {
+		args = append(args, &lib.Arg{
+			Name:        "ipOrCIDR",
+			Type:        lib.ArgTypeStringList,
+			Description: "IPs or CIDRs to add to or remove from the list, used with name",
+		})
+	}
+	args = append(args,
+		&lib.Arg{
+			Name:        "inputDir",
+			Type:        lib.ArgTypeString,
+			Description: "Directory of files to read, with file names as list names",
+		},
+		&lib.Arg{
+			Name:        "wantedList",
+			Type:        lib.ArgTypeStringList,
+			Description: "The only files to read in inputDir",
+		},
+		lib.ArgOnlyIPType(),
+	)
+	if iType == TypeJSONIn {
+		args = append(args, &lib.Arg{
+			Name:        "jsonPath",
+			Type:        lib.ArgTypeStringList,
+			Description: "Paths of IPs and CIDRs in the JSON data, in the syntax of gjson",
+			Required:    true,
+		})
+	}
+	args = append(args,
+		&lib.Arg{
+			Name:        "removePrefixesInLine",
+			Type:        lib.ArgTypeStringList,
+			Description: "Prefixes to remove from each line",
+		},
+		&lib.Arg{
+			Name:        "removeSuffixesInLine",
+			Type:        lib.ArgTypeStringList,
+			Description: "Suffixes to remove from each line",
+		},
+		lib.ArgHTTP(),
+	)
+
+	example := map[string]any{
+		"name": "cn",
+		"uri":  "./cn.txt",
+	}
+	switch iType {
+	case TypeJSONIn:
+		example = map[string]any{
+			"name":     "fastly",
+			"uri":      "https://api.fastly.com/public-ip-list",
+			"jsonPath": []string{"addresses", "ipv6_addresses"},
+		}
+	case TypeClashRuleSetClassicalIn, TypeClashRuleSetIPCIDRIn:
+		example["uri"] = "./cn.yaml"
+	}
+
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args:    append(args, lib.ArgsChecksum()...),
+		Example: example,
+	}
 }
 
 func newTextIn(iType string, iDesc string, action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -38,6 +117,9 @@ func newTextIn(iType string, iDesc string, action lib.Action, data json.RawMessa
 		JSONPath             []string `json:"jsonPath"`
 		RemovePrefixesInLine []string `json:"removePrefixesInLine"`
 		RemoveSuffixesInLine []string `json:"removeSuffixesInLine"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
 	}
 
 	if strings.TrimSpace(iType) == "" {
@@ -69,6 +151,15 @@ func newTextIn(iType string, iDesc string, action lib.Action, data json.RawMessa
 		return nil, fmt.Errorf("❌ [type %s | action %s] inputDir is not allowed to be used with name or uri or ipOrCIDR", iType, action)
 	}
 
+	// Files in inputDir can only be verified by a checksum file with their filenames
+	checksum := tmp.Checksum
+	if tmp.InputDir != "" {
+		if tmp.SHA256 != "" {
+			return nil, fmt.Errorf("❌ [type %s | action %s] sha256 cannot be used with inputDir, use sha256URI instead", iType, action)
+		}
+		checksum = checksum.ForFiles(false)
+	}
+
 	// Filter want list
 	wantList := make(map[string]bool)
 	for _, want := range tmp.Want {
@@ -91,6 +182,9 @@ func newTextIn(iType string, iDesc string, action lib.Action, data json.RawMessa
 		JSONPath:             tmp.JSONPath,
 		RemovePrefixesInLine: tmp.RemovePrefixesInLine,
 		RemoveSuffixesInLine: tmp.RemoveSuffixesInLine,
+
+		HTTP:     tmp.HTTP,
+		Checksum: checksum,
 	}, nil
 }
 
@@ -106,7 +200,51 @@ func (t *TextIn) GetDescription() string {
 	return t.Description
 }
 
+func (t *TextIn) ResolvedArgs() map[string]any {
+	return lib.ListArgs(slices.Collect(maps.Keys(t.Want)), nil, t.OnlyIPType)
+}
+
+func (t *TextIn) Prefetch() error {
+	entries, err := t.loadEntries()
+	if err != nil {
+		return err
+	}
+
+	t.prefetched = entries
+	return nil
+}
+
 func (t *TextIn) Input(container lib.Container) (lib.Container, error) {
+	entries := t.prefetched
+	t.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = t.loadEntries(); err != nil {
+			return nil, err
+		}
+	}
+
+	ignoreIPType := lib.GetIgnoreIPType(t.OnlyIPType)
+
+	for _, entry := range entries {
+		switch t.Action {
+		case lib.ActionAdd:
+			if err := container.Add(entry, ignoreIPType); err != nil {
+				return nil, err
+			}
+		case lib.ActionRemove:
+			if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, lib.ErrUnknownAction
+		}
+	}
+
+	return container, nil
+}
+
+func (t *TextIn) loadEntries() (map[string]*lib.Entry, error) {
 	entries := make(map[string]*lib.Entry)
 	var err error
 
@@ -138,28 +276,11 @@ func (t *TextIn) Input(container lib.Container) (lib.Container, error) {
 		return nil, err
 	}
 
-	ignoreIPType := lib.GetIgnoreIPType(t.OnlyIPType)
-
 	if len(entries) == 0 {
 		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", t.Type, t.Action)
 	}
 
-	for _, entry := range entries {
-		switch t.Action {
-		case lib.ActionAdd:
-			if err := container.Add(entry, ignoreIPType); err != nil {
-				return nil, err
-			}
-		case lib.ActionRemove:
-			if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
-				return nil, err
-			}
-		default:
-			return nil, lib.ErrUnknownAction
-		}
-	}
-
-	return container, nil
+	return entries, nil
 }
 
 func (t *TextIn) walkDir(dir string, entries map[string]*lib.Entry) error {
@@ -211,7 +332,7 @@ func (t *TextIn) walkLocalFile(path, name string, entries map[string]*lib.Entry)
 	}
 
 	entry := lib.NewEntry(entryName)
-	file, err := os.Open(path)
+	file, err := lib.OpenURI(path, &t.Checksum, t.HTTP)
 	if err != nil {
 		return err
 	}
@@ -226,15 +347,11 @@ func (t *TextIn) walkLocalFile(path, name string, entries map[string]*lib.Entry)
 }
 
 func (t *TextIn) walkRemoteFile(url, name string, entries map[string]*lib.Entry) error {
-	resp, err := http.Get(url)
+	body, err := lib.OpenURI(url, &t.Checksum, t.HTTP)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("❌ [type %s | action %s] failed to get remote file %s, http status code %d", t.Type, t.Action, url, resp.StatusCode)
+		return fmt.Errorf("❌ [type %s | action %s] %v", t.Type, t.Action, err)
 	}
+	defer body.Close()
 
 	name = strings.ToUpper(name)
 
@@ -243,7 +360,7 @@ func (t *TextIn) walkRemoteFile(url, name string, entries map[string]*lib.Entry)
 	}
 
 	entry := lib.NewEntry(name)
-	if err := t.scanFile(resp.Body, entry); err != nil {
+	if err := t.scanFile(body, entry); err != nil {
 		return err
 	}
 
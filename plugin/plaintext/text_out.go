@@ -21,6 +21,7 @@ func init() {
 	lib.RegisterOutputConverter(TypeTextOut, &TextOut{
 		Description: DescTextOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeTextOut, newTextOutSchema(TypeTextOut))
 }
 
 func (t *TextOut) GetType() string {
@@ -35,12 +36,19 @@ func (t *TextOut) GetDescription() string {
 	return t.Description
 }
 
+func (t *TextOut) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(t.Want, t.Exclude, t.OnlyIPType)
+	args["outputDir"] = t.OutputDir
+	args["outputExtension"] = t.OutputExt
+	return args
+}
+
 func (t *TextOut) Output(container lib.Container) error {
-	for _, name := range t.filterAndSortList(container) {
+	return lib.ForEachConcurrently(t.filterAndSortList(container), func(name string) error {
 		entry, found := container.GetEntry(name)
 		if !found {
 			log.Printf("❌ entry %s not found\n", name)
-			continue
+			return nil
 		}
 
 		data, err := t.marshalBytes(entry)
@@ -49,12 +57,8 @@ func (t *TextOut) Output(container lib.Container) error {
 		}
 
 		filename := strings.ToLower(entry.GetName()) + t.OutputExt
-		if err := t.writeFile(filename, data); err != nil {
-			return err
-		}
-	}
-
-	return nil
+		return t.writeFile(filename, data)
+	})
 }
 
 func (t *TextOut) filterAndSortList(container lib.Container) []string {
@@ -4,10 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"net/http"
+	"maps"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -26,6 +27,41 @@ func init() {
 	lib.RegisterInputConverter(TypeSRSIn, &SRSIn{
 		Description: DescSRSIn,
 	})
+	lib.RegisterInputConverterSchema(TypeSRSIn, newSRSInSchema())
+}
+
+func newSRSInSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: append([]*lib.Arg{
+			{
+				Name:        "name",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the list, used with uri",
+			},
+			{
+				Name:        "uri",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the SRS file, used with name",
+			},
+			{
+				Name:        "inputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory of SRS files to read, with file names as list names",
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only files to read in inputDir",
+			},
+			lib.ArgOnlyIPType(),
+			lib.ArgHTTP(),
+		}, lib.ArgsChecksum()...),
+		Example: map[string]any{
+			"name": "cn",
+			"uri":  "./cn.srs",
+		},
+	}
 }
 
 func newSRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -35,6 +71,9 @@ func newSRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, erro
 		InputDir   string     `json:"inputDir"`
 		Want       []string   `json:"wantedList"`
 		OnlyIPType lib.IPType `json:"onlyIPType"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
 	}
 
 	if len(data) > 0 {
@@ -51,6 +90,15 @@ func newSRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, erro
 		return nil, fmt.Errorf("❌ [type %s | action %s] name & uri must be specified together", TypeSRSIn, action)
 	}
 
+	// Files in inputDir can only be verified by a checksum file with their filenames
+	checksum := tmp.Checksum
+	if tmp.InputDir != "" {
+		if tmp.SHA256 != "" {
+			return nil, fmt.Errorf("❌ [type %s | action %s] sha256 cannot be used with inputDir, use sha256URI instead", TypeSRSIn, action)
+		}
+		checksum = checksum.ForFiles(false)
+	}
+
 	// Filter want list
 	wantList := make(map[string]bool)
 	for _, want := range tmp.Want {
@@ -68,6 +116,9 @@ func newSRSIn(action lib.Action, data json.RawMessage) (lib.InputConverter, erro
 		InputDir:    tmp.InputDir,
 		Want:        wantList,
 		OnlyIPType:  tmp.OnlyIPType,
+
+		HTTP:     tmp.HTTP,
+		Checksum: checksum,
 	}, nil
 }
 
@@ -80,6 +131,11 @@ type SRSIn struct {
 	InputDir    string
 	Want        map[string]bool
 	OnlyIPType  lib.IPType
+
+	HTTP     *lib.HTTPConfig
+	Checksum lib.Checksum
+
+	prefetched map[string]*lib.Entry
 }
 
 func (s *SRSIn) GetType() string {
@@ -94,7 +150,51 @@ func (s *SRSIn) GetDescription() string {
 	return s.Description
 }
 
+func (s *SRSIn) ResolvedArgs() map[string]any {
+	return lib.ListArgs(slices.Collect(maps.Keys(s.Want)), nil, s.OnlyIPType)
+}
+
+func (s *SRSIn) Prefetch() error {
+	entries, err := s.loadEntries()
+	if err != nil {
+		return err
+	}
+
+	s.prefetched = entries
+	return nil
+}
+
 func (s *SRSIn) Input(container lib.Container) (lib.Container, error) {
+	entries := s.prefetched
+	s.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = s.loadEntries(); err != nil {
+			return nil, err
+		}
+	}
+
+	ignoreIPType := lib.GetIgnoreIPType(s.OnlyIPType)
+
+	for _, entry := range entries {
+		switch s.Action {
+		case lib.ActionAdd:
+			if err := container.Add(entry, ignoreIPType); err != nil {
+				return nil, err
+			}
+		case lib.ActionRemove:
+			if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, lib.ErrUnknownAction
+		}
+	}
+
+	return container, nil
+}
+
+func (s *SRSIn) loadEntries() (map[string]*lib.Entry, error) {
 	entries := make(map[string]*lib.Entry)
 	var err error
 
@@ -120,24 +220,7 @@ func (s *SRSIn) Input(container lib.Container) (lib.Container, error) {
 		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", s.Type, s.Action)
 	}
 
-	ignoreIPType := lib.GetIgnoreIPType(s.OnlyIPType)
-
-	for _, entry := range entries {
-		switch s.Action {
-		case lib.ActionAdd:
-			if err := container.Add(entry, ignoreIPType); err != nil {
-				return nil, err
-			}
-		case lib.ActionRemove:
-			if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
-				return nil, err
-			}
-		default:
-			return nil, lib.ErrUnknownAction
-		}
-	}
-
-	return container, nil
+	return entries, nil
 }
 
 func (s *SRSIn) walkDir(dir string, entries map[string]*lib.Entry) error {
@@ -184,7 +267,7 @@ func (s *SRSIn) walkLocalFile(path, name string, entries map[string]*lib.Entry)
 		return fmt.Errorf("❌ [type %s | action %s] found duplicated list %s", s.Type, s.Action, entryName)
 	}
 
-	file, err := os.Open(path)
+	file, err := lib.OpenURI(path, &s.Checksum, s.HTTP)
 	if err != nil {
 		return err
 	}
@@ -198,17 +281,13 @@ func (s *SRSIn) walkLocalFile(path, name string, entries map[string]*lib.Entry)
 }
 
 func (s *SRSIn) walkRemoteFile(url, name string, entries map[string]*lib.Entry) error {
-	resp, err := http.Get(url)
+	body, err := lib.OpenURI(url, &s.Checksum, s.HTTP)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("❌ [type %s | action %s] failed to get remote file %s, http status code %d", s.Type, s.Action, url, resp.StatusCode)
+		return fmt.Errorf("❌ [type %s | action %s] %v", s.Type, s.Action, err)
 	}
+	defer body.Close()
 
-	if err := s.generateEntries(name, resp.Body, entries); err != nil {
+	if err := s.generateEntries(name, body, entries); err != nil {
 		return err
 	}
 
@@ -31,6 +31,27 @@ func init() {
 	lib.RegisterOutputConverter(TypeSRSOut, &SRSOut{
 		Description: DescSRSOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeSRSOut, newSRSOutSchema())
+}
+
+func newSRSOutSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "outputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory to write SRS files to, one file per list",
+				Default:     defaultOutputDir,
+			},
+			lib.ArgWantedList(),
+			lib.ArgExcludedList(),
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"wantedList": []string{"cn", "private"},
+		},
+	}
 }
 
 func newSRSOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
@@ -84,20 +105,22 @@ func (s *SRSOut) GetDescription() string {
 	return s.Description
 }
 
+func (s *SRSOut) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(s.Want, s.Exclude, s.OnlyIPType)
+	args["outputDir"] = s.OutputDir
+	return args
+}
+
 func (s *SRSOut) Output(container lib.Container) error {
-	for _, name := range s.filterAndSortList(container) {
+	return lib.ForEachConcurrently(s.filterAndSortList(container), func(name string) error {
 		entry, found := container.GetEntry(name)
 		if !found {
 			log.Printf("❌ entry %s not found\n", name)
-			continue
+			return nil
 		}
 
-		if err := s.generate(entry); err != nil {
-			return err
-		}
-	}
-
-	return nil
+		return s.generate(entry)
+	})
 }
 
 func (s *SRSOut) filterAndSortList(container lib.Container) []string {
@@ -174,6 +197,10 @@ func (s *SRSOut) marshalRuleSet(entry *lib.Entry) (*option.PlainRuleSet, error)
 	return nil, fmt.Errorf("❌ [type %s | action %s] entry %s has no CIDR", s.Type, s.Action, entry.GetName())
 }
 
+func (s *SRSOut) OutputFiles() []string {
+	return lib.ListFiles(s.OutputDir, ".srs", s.Want, s.Exclude)
+}
+
 func (s *SRSOut) writeFile(filename string, ruleset *option.PlainRuleSet) error {
 	if err := os.MkdirAll(s.OutputDir, 0755); err != nil {
 		return err
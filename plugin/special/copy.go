@@ -0,0 +1,154 @@
+package special
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+const (
+	TypeCopy = "copy"
+	DescCopy = "Copy, rename or merge lists from previous steps into a new list"
+)
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeCopy, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newCopy(action, data)
+	})
+	lib.RegisterInputConverter(TypeCopy, &Copy{
+		Description: DescCopy,
+	})
+	lib.RegisterInputConverterSchema(TypeCopy, newCopySchema())
+}
+
+func newCopySchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd},
+		Args: []*lib.Arg{
+			{
+				Name:        "name",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the list to copy to",
+				Required:    true,
+			},
+			{
+				Name:        "sourceList",
+				Type:        lib.ArgTypeStringList,
+				Description: "Lists to copy from",
+				Required:    true,
+			},
+			{
+				Name:        "removeSourceList",
+				Type:        lib.ArgTypeBoolean,
+				Description: "Remove source lists after copying",
+				Default:     false,
+			},
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"name":       "china",
+			"sourceList": []string{"cn"},
+		},
+	}
+}
+
+func newCopy(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp struct {
+		Name         string     `json:"name"`
+		Source       []string   `json:"sourceList"`
+		RemoveSource bool       `json:"removeSourceList"`
+		OnlyIPType   lib.IPType `json:"onlyIPType"`
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return nil, err
+		}
+	}
+
+	if action != lib.ActionAdd {
+		return nil, fmt.Errorf("❌ [type %s] only supports `add` action", TypeCopy)
+	}
+
+	tmp.Name = strings.ToUpper(strings.TrimSpace(tmp.Name))
+	if tmp.Name == "" {
+		return nil, fmt.Errorf("❌ [type %s | action %s] missing name", TypeCopy, action)
+	}
+
+	// Filter source list
+	sourceList := make([]string, 0, len(tmp.Source))
+	sourceMap := make(map[string]bool)
+	for _, source := range tmp.Source {
+		if source = strings.ToUpper(strings.TrimSpace(source)); source != "" && !sourceMap[source] {
+			sourceList = append(sourceList, source)
+			sourceMap[source] = true
+		}
+	}
+
+	if len(sourceList) == 0 {
+		return nil, fmt.Errorf("❌ [type %s | action %s] sourceList must be specified", TypeCopy, action)
+	}
+
+	return &Copy{
+		Type:         TypeCopy,
+		Action:       action,
+		Description:  DescCopy,
+		Name:         tmp.Name,
+		Source:       sourceList,
+		RemoveSource: tmp.RemoveSource,
+		OnlyIPType:   tmp.OnlyIPType,
+	}, nil
+}
+
+type Copy struct {
+	Type         string
+	Action       lib.Action
+	Description  string
+	Name         string
+	Source       []string
+	RemoveSource bool
+	OnlyIPType   lib.IPType
+}
+
+func (c *Copy) GetType() string {
+	return c.Type
+}
+
+func (c *Copy) GetAction() lib.Action {
+	return c.Action
+}
+
+func (c *Copy) GetDescription() string {
+	return c.Description
+}
+
+func (c *Copy) ResolvedArgs() map[string]any {
+	return lib.ListArgs(nil, nil, c.OnlyIPType)
+}
+
+func (c *Copy) Input(container lib.Container) (lib.Container, error) {
+	ignoreIPType := lib.GetIgnoreIPType(c.OnlyIPType)
+
+	if err := container.Copy(c.Name, c.Source, ignoreIPType); err != nil {
+		return nil, fmt.Errorf("❌ [type %s | action %s] %v", c.Type, c.Action, err)
+	}
+
+	if !c.RemoveSource {
+		return container, nil
+	}
+
+	// Remove source lists to rename them to the new list
+	for _, source := range c.Source {
+		if source == c.Name {
+			continue
+		}
+
+		if err := container.Remove(lib.NewEntry(source), lib.CaseRemoveEntry, ignoreIPType); err != nil {
+			return nil, err
+		}
+	}
+
+	return container, nil
+}
@@ -0,0 +1,153 @@
+package special
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+// containerLists returns the prefixes of every list in container.
+func containerLists(t *testing.T, container lib.Container) map[string][]string {
+	t.Helper()
+	lists := make(map[string][]string)
+	for entry := range container.Loop() {
+		prefixes, err := entry.MarshalText()
+		if err != nil {
+			t.Fatal(err)
+		}
+		lists[entry.GetName()] = prefixes
+	}
+	return lists
+}
+
+func TestCopyInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    string
+		want    map[string][]string
+		wantErr string
+	}{
+		{
+			name: "multiple sources into new list",
+			args: `{"name": "asia", "sourceList": ["cn", "hk"]}`,
+			want: map[string][]string{
+				"ASIA": {"1.0.0.0/16", "2.0.0.0/16", "2001:250::/32"},
+				"CN":   {"1.0.0.0/16", "2001:250::/32"},
+				"HK":   {"2.0.0.0/16"},
+				"JP":   {"3.0.0.0/16"},
+			},
+		},
+		{
+			name: "merge into existing list",
+			args: `{"name": "cn", "sourceList": ["hk"]}`,
+			want: map[string][]string{
+				"CN": {"1.0.0.0/16", "2.0.0.0/16", "2001:250::/32"},
+				"HK": {"2.0.0.0/16"},
+				"JP": {"3.0.0.0/16"},
+			},
+		},
+		{
+			name: "remove source lists",
+			args: `{"name": "asia", "sourceList": ["cn", "hk"], "removeSourceList": true}`,
+			want: map[string][]string{
+				"ASIA": {"1.0.0.0/16", "2.0.0.0/16", "2001:250::/32"},
+				"JP":   {"3.0.0.0/16"},
+			},
+		},
+		{
+			name: "remove source lists except the target",
+			args: `{"name": "cn", "sourceList": ["CN", "hk"], "removeSourceList": true}`,
+			want: map[string][]string{
+				"CN": {"1.0.0.0/16", "2.0.0.0/16", "2001:250::/32"},
+				"JP": {"3.0.0.0/16"},
+			},
+		},
+		{
+			name: "only ipv6",
+			args: `{"name": "asia", "sourceList": ["cn", "hk"], "onlyIPType": "ipv6"}`,
+			want: map[string][]string{
+				"ASIA": {"2001:250::/32"},
+				"CN":   {"1.0.0.0/16", "2001:250::/32"},
+				"HK":   {"2.0.0.0/16"},
+				"JP":   {"3.0.0.0/16"},
+			},
+		},
+		{
+			name: "only ipv4 removes ipv4 of sources",
+			args: `{"name": "asia", "sourceList": ["cn"], "onlyIPType": "ipv4", "removeSourceList": true}`,
+			want: map[string][]string{
+				"ASIA": {"1.0.0.0/16"},
+				"CN":   {"2001:250::/32"},
+				"HK":   {"2.0.0.0/16"},
+				"JP":   {"3.0.0.0/16"},
+			},
+		},
+		{
+			name:    "missing source list",
+			args:    `{"name": "asia", "sourceList": ["cn", "kr"]}`,
+			wantErr: "entry KR not found",
+		},
+		{
+			name:    "no prefix of ip type",
+			args:    `{"name": "asia", "sourceList": ["hk"], "onlyIPType": "ipv6"}`,
+			wantErr: "has no prefix to copy",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			converter, err := newCopy(lib.ActionAdd, json.RawMessage(tt.args))
+			if err != nil {
+				t.Fatalf("newCopy(%s) error = %v", tt.args, err)
+			}
+
+			container, err := lib.NewContainerFromMap(map[string][]string{
+				"cn": {"1.0.0.0/16", "2001:250::/32"},
+				"hk": {"2.0.0.0/16"},
+				"jp": {"3.0.0.0/16"},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			container, err = converter.Input(container)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Input(%s) error = %v, want error containing %q", tt.args, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Input(%s) error = %v", tt.args, err)
+			}
+
+			got := containerLists(t, container)
+			if !maps.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("Input(%s) = %v, want %v", tt.args, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewCopyError(t *testing.T) {
+	tests := []struct {
+		action  lib.Action
+		args    string
+		wantErr string
+	}{
+		{action: lib.ActionRemove, args: `{"name": "asia", "sourceList": ["cn"]}`, wantErr: "only supports `add` action"},
+		{action: lib.ActionAdd, args: `{"name": " ", "sourceList": ["cn"]}`, wantErr: "missing name"},
+		{action: lib.ActionAdd, args: `{"name": "asia", "sourceList": [" "]}`, wantErr: "sourceList must be specified"},
+	}
+
+	for _, tt := range tests {
+		_, err := newCopy(tt.action, json.RawMessage(tt.args))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("newCopy(%s, %s) error = %v, want error containing %q", tt.action, tt.args, err, tt.wantErr)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package special
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -20,6 +22,26 @@ func init() {
 	lib.RegisterInputConverter(TypeCutter, &Cutter{
 		Description: DescCutter,
 	})
+	lib.RegisterInputConverterSchema(TypeCutter, newCutterSchema())
+}
+
+func newCutterSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionRemove},
+		Args: []*lib.Arg{
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "Lists to remove",
+				Required:    true,
+			},
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"wantedList": []string{"cn"},
+			"onlyIPType": lib.IPv6,
+		},
+	}
 }
 
 func newCutter(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -79,6 +101,10 @@ func (c *Cutter) GetDescription() string {
 	return c.Description
 }
 
+func (c *Cutter) ResolvedArgs() map[string]any {
+	return lib.ListArgs(slices.Collect(maps.Keys(c.Want)), nil, c.OnlyIPType)
+}
+
 func (c *Cutter) Input(container lib.Container) (lib.Container, error) {
 	ignoreIPType := lib.GetIgnoreIPType(c.OnlyIPType)
 
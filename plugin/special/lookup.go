@@ -23,12 +23,50 @@ func init() {
 	lib.RegisterOutputConverter(TypeLookup, &Lookup{
 		Description: DescLookup,
 	})
+	lib.RegisterOutputConverterSchema(TypeLookup, newLookupSchema())
+}
+
+func newLookupSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "search",
+				Type:        lib.ArgTypeString,
+				Description: "IP or CIDR to look up",
+				Required:    true,
+			},
+			{
+				Name:        "searchList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only lists to search in",
+			},
+			{
+				Name:        "detail",
+				Type:        lib.ArgTypeBoolean,
+				Description: "Print the matched prefix and containment of each list",
+				Default:     false,
+			},
+			{
+				Name:        "overlap",
+				Type:        lib.ArgTypeBoolean,
+				Description: "Print the overlapping prefixes of each list with a CIDR",
+				Default:     false,
+			},
+		},
+		Example: map[string]any{
+			"search":     "1.1.1.1",
+			"searchList": []string{"cn", "us"},
+		},
+	}
 }
 
 func newLookup(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
 	var tmp struct {
 		Search     string   `json:"search"`
 		SearchList []string `json:"searchList"`
+		Detail     bool     `json:"detail"`
+		Overlap    bool     `json:"overlap"`
 	}
 
 	if len(data) > 0 {
@@ -42,12 +80,18 @@ func newLookup(action lib.Action, data json.RawMessage) (lib.OutputConverter, er
 		return nil, fmt.Errorf("❌ [type %s | action %s] please specify an IP or a CIDR as search target", TypeLookup, action)
 	}
 
+	if tmp.Detail && tmp.Overlap {
+		return nil, fmt.Errorf("❌ [type %s | action %s] detail and overlap cannot be used together", TypeLookup, action)
+	}
+
 	return &Lookup{
 		Type:        TypeLookup,
 		Action:      action,
 		Description: DescLookup,
 		Search:      tmp.Search,
 		SearchList:  tmp.SearchList,
+		Detail:      tmp.Detail,
+		Overlap:     tmp.Overlap,
 	}, nil
 }
 
@@ -57,6 +101,8 @@ type Lookup struct {
 	Description string
 	Search      string
 	SearchList  []string
+	Detail      bool
+	Overlap     bool
 }
 
 func (l *Lookup) GetType() string {
@@ -71,6 +117,10 @@ func (l *Lookup) GetDescription() string {
 	return l.Description
 }
 
+func (l *Lookup) OutputsToStdout() bool {
+	return true
+}
+
 func (l *Lookup) Output(container lib.Container) error {
 	switch strings.Contains(l.Search, "/") {
 	case true: // CIDR
@@ -84,6 +134,13 @@ func (l *Lookup) Output(container lib.Container) error {
 		}
 	}
 
+	switch {
+	case l.Detail:
+		return l.outputDetail(container)
+	case l.Overlap:
+		return l.outputOverlap(container)
+	}
+
 	lists, found, _ := container.Lookup(l.Search, l.SearchList...)
 	if found {
 		slices.Sort(lists)
@@ -94,3 +151,55 @@ func (l *Lookup) Output(container lib.Container) error {
 
 	return nil
 }
+
+// outputDetail prints the lists containing or overlapping the IP or CIDR
+// in JSON format, with the matched prefix and containment type of each list.
+func (l *Lookup) outputDetail(container lib.Container) error {
+	results, err := container.LookupDetail(l.Search, l.SearchList...)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("false")
+		return nil
+	}
+
+	for _, result := range results {
+		result.List = strings.ToLower(result.List)
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	return nil
+}
+
+// outputOverlap prints the lists intersecting the IP or CIDR in JSON format,
+// with the intersected prefixes and the fraction of addresses covered by each list.
+func (l *Lookup) outputOverlap(container lib.Container) error {
+	results, err := container.LookupOverlap(l.Search, l.SearchList...)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("false")
+		return nil
+	}
+
+	for _, result := range results {
+		result.List = strings.ToLower(result.List)
+	}
+
+	data, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+
+	return nil
+}
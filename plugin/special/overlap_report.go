@@ -0,0 +1,365 @@
+package special
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"go4.org/netipx"
+)
+
+const (
+	TypeOverlapReport = "overlapReport"
+	DescOverlapReport = "Report overlapping CIDRs between each pair of lists"
+)
+
+var defaultOutputDirForOverlapReport = filepath.Join("./", "output", "report")
+
+func init() {
+	lib.RegisterOutputConfigCreator(TypeOverlapReport, func(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+		return newOverlapReport(action, data)
+	})
+	lib.RegisterOutputConverter(TypeOverlapReport, &OverlapReport{
+		Description: DescOverlapReport,
+	})
+	lib.RegisterOutputConverterSchema(TypeOverlapReport, newOverlapReportSchema())
+}
+
+func newOverlapReportSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "outputName",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the report file, overlap.json by default, or overlap.txt in text format",
+			},
+			{
+				Name:        "outputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory to write the report file to",
+				Default:     defaultOutputDirForOverlapReport,
+			},
+			{
+				Name:        "format",
+				Type:        lib.ArgTypeString,
+				Description: "Format of the report",
+				Default:     "json",
+				Enum:        []string{"json", "text"},
+			},
+			lib.ArgWantedList(),
+			lib.ArgExcludedList(),
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"format":     "text",
+			"wantedList": []string{"cn", "cloudflare"},
+		},
+	}
+}
+
+func newOverlapReport(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
+	var tmp struct {
+		OutputName string     `json:"outputName"`
+		OutputDir  string     `json:"outputDir"`
+		Format     string     `json:"format"`
+		Want       []string   `json:"wantedList"`
+		Exclude    []string   `json:"excludedList"`
+		OnlyIPType lib.IPType `json:"onlyIPType"`
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return nil, err
+		}
+	}
+
+	tmp.Format = strings.ToLower(strings.TrimSpace(tmp.Format))
+	switch tmp.Format {
+	case "":
+		tmp.Format = "json"
+	case "json", "text":
+	default:
+		return nil, fmt.Errorf("❌ [type %s | action %s] invalid format %s, available formats: json, text", TypeOverlapReport, action, tmp.Format)
+	}
+
+	if tmp.OutputName == "" {
+		if tmp.Format == "json" {
+			tmp.OutputName = "overlap.json"
+		} else {
+			tmp.OutputName = "overlap.txt"
+		}
+	}
+
+	if tmp.OutputDir == "" {
+		tmp.OutputDir = defaultOutputDirForOverlapReport
+	}
+
+	return &OverlapReport{
+		Type:        TypeOverlapReport,
+		Action:      action,
+		Description: DescOverlapReport,
+		OutputName:  tmp.OutputName,
+		OutputDir:   tmp.OutputDir,
+		Format:      tmp.Format,
+		Want:        tmp.Want,
+		Exclude:     tmp.Exclude,
+		OnlyIPType:  tmp.OnlyIPType,
+	}, nil
+}
+
+type OverlapReport struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	OutputName  string
+	OutputDir   string
+	Format      string
+	Want        []string
+	Exclude     []string
+	OnlyIPType  lib.IPType
+}
+
+func (o *OverlapReport) GetType() string {
+	return o.Type
+}
+
+func (o *OverlapReport) GetAction() lib.Action {
+	return o.Action
+}
+
+func (o *OverlapReport) GetDescription() string {
+	return o.Description
+}
+
+func (o *OverlapReport) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(o.Want, o.Exclude, o.OnlyIPType)
+	args["outputName"] = o.OutputName
+	args["outputDir"] = o.OutputDir
+	args["format"] = o.Format
+	return args
+}
+
+// overlapResult is the report written to file. IPv4Matrix and IPv6Matrix
+// hold the number of addresses shared by each pair of lists, in the order
+// of Lists, with the number of addresses of each list on the diagonal.
+type overlapResult struct {
+	Lists      []string      `json:"lists"`
+	IPv4Matrix [][]*big.Int  `json:"ipv4Matrix"`
+	IPv6Matrix [][]*big.Int  `json:"ipv6Matrix"`
+	Overlaps   []*overlapped `json:"overlaps"`
+}
+
+type overlapped struct {
+	Lists            [2]string      `json:"lists"`
+	IPv4AddressCount *big.Int       `json:"ipv4AddressCount"`
+	IPv6AddressCount *big.Int       `json:"ipv6AddressCount"`
+	Prefixes         []netip.Prefix `json:"prefixes"`
+}
+
+func (o *OverlapReport) Output(container lib.Container) error {
+	ignoreIPType := lib.GetIgnoreIPType(o.OnlyIPType)
+
+	lists := make([]string, 0, 300)
+	ipsets := make([]*netipx.IPSet, 0, 300)
+	for _, name := range o.filterAndSortList(container) {
+		entry, found := container.GetEntry(name)
+		if !found {
+			log.Printf("❌ entry %s not found\n", name)
+			continue
+		}
+
+		ipset, err := entry.GetIPSet(ignoreIPType)
+		if err != nil {
+			return err
+		}
+
+		lists = append(lists, name)
+		ipsets = append(ipsets, ipset)
+	}
+
+	result := &overlapResult{
+		Lists:      lists,
+		IPv4Matrix: make([][]*big.Int, len(lists)),
+		IPv6Matrix: make([][]*big.Int, len(lists)),
+		Overlaps:   make([]*overlapped, 0),
+	}
+	for i := range lists {
+		result.IPv4Matrix[i] = make([]*big.Int, len(lists))
+		result.IPv6Matrix[i] = make([]*big.Int, len(lists))
+	}
+
+	// Each row only computes intersections with lists after it,
+	// so rows can be computed concurrently without sharing any cell.
+	rowOverlaps := make([][]*overlapped, len(lists))
+	rows := make([]int, len(lists))
+	for i := range rows {
+		rows[i] = i
+	}
+	err := lib.ForEachConcurrently(rows, func(i int) error {
+		ipv4Count, ipv6Count := countAddressesByIPType(ipsets[i].Prefixes())
+		result.IPv4Matrix[i][i], result.IPv6Matrix[i][i] = ipv4Count, ipv6Count
+
+		for j := i + 1; j < len(lists); j++ {
+			var builder netipx.IPSetBuilder
+			builder.AddSet(ipsets[i])
+			builder.Intersect(ipsets[j])
+			intersection, err := builder.IPSet()
+			if err != nil {
+				return err
+			}
+
+			prefixes := intersection.Prefixes()
+			ipv4Count, ipv6Count := countAddressesByIPType(prefixes)
+			result.IPv4Matrix[i][j], result.IPv4Matrix[j][i] = ipv4Count, ipv4Count
+			result.IPv6Matrix[i][j], result.IPv6Matrix[j][i] = ipv6Count, ipv6Count
+
+			if len(prefixes) > 0 {
+				rowOverlaps[i] = append(rowOverlaps[i], &overlapped{
+					Lists:            [2]string{lists[i], lists[j]},
+					IPv4AddressCount: ipv4Count,
+					IPv6AddressCount: ipv6Count,
+					Prefixes:         prefixes,
+				})
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, overlaps := range rowOverlaps {
+		result.Overlaps = append(result.Overlaps, overlaps...)
+	}
+
+	var data []byte
+	switch o.Format {
+	case "json":
+		data, err = json.MarshalIndent(result, "", "  ")
+	default:
+		data, err = result.marshalText()
+	}
+	if err != nil {
+		return err
+	}
+
+	return o.writeFile(data)
+}
+
+func (o *OverlapReport) filterAndSortList(container lib.Container) []string {
+	excludeMap := make(map[string]bool)
+	for _, exclude := range o.Exclude {
+		if exclude = strings.ToUpper(strings.TrimSpace(exclude)); exclude != "" {
+			excludeMap[exclude] = true
+		}
+	}
+
+	wantList := make([]string, 0, len(o.Want))
+	for _, want := range o.Want {
+		if want = strings.ToUpper(strings.TrimSpace(want)); want != "" && !excludeMap[want] {
+			wantList = append(wantList, want)
+		}
+	}
+
+	if len(wantList) > 0 {
+		// Sort the list
+		slices.Sort(wantList)
+		return wantList
+	}
+
+	list := make([]string, 0, 300)
+	for entry := range container.Loop() {
+		name := entry.GetName()
+		if excludeMap[name] {
+			continue
+		}
+		list = append(list, name)
+	}
+
+	// Sort the list
+	slices.Sort(list)
+
+	return list
+}
+
+func (o *OverlapReport) OutputFiles() []string {
+	return []string{filepath.Join(o.OutputDir, o.OutputName)}
+}
+
+func (o *OverlapReport) writeFile(data []byte) error {
+	if err := os.MkdirAll(o.OutputDir, 0755); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(filepath.Join(o.OutputDir, o.OutputName), data, 0644); err != nil {
+		return err
+	}
+
+	log.Printf("✅ [%s] %s --> %s", o.Type, o.OutputName, o.OutputDir)
+
+	return nil
+}
+
+func countAddressesByIPType(prefixes []netip.Prefix) (*big.Int, *big.Int) {
+	// IPv4 prefixes are sorted before IPv6 ones
+	index := len(prefixes)
+	for i, prefix := range prefixes {
+		if prefix.Addr().Is6() {
+			index = i
+			break
+		}
+	}
+
+	return lib.CountAddresses(prefixes[:index]), lib.CountAddresses(prefixes[index:])
+}
+
+func (r *overlapResult) marshalText() ([]byte, error) {
+	var buf bytes.Buffer
+
+	for _, matrix := range []struct {
+		title  string
+		matrix [][]*big.Int
+	}{
+		{"IPv4 addresses shared by lists", r.IPv4Matrix},
+		{"IPv6 addresses shared by lists", r.IPv6Matrix},
+	} {
+		buf.WriteString(matrix.title + ":\n")
+
+		w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', tabwriter.AlignRight)
+		fmt.Fprintf(w, "\t%s\t\n", strings.Join(r.Lists, "\t"))
+		for i, row := range matrix.matrix {
+			cells := make([]string, 0, len(row))
+			for _, cell := range row {
+				cells = append(cells, cell.String())
+			}
+			fmt.Fprintf(w, "%s\t%s\t\n", r.Lists[i], strings.Join(cells, "\t"))
+		}
+		if err := w.Flush(); err != nil {
+			return nil, err
+		}
+
+		buf.WriteString("\n")
+	}
+
+	for _, overlap := range r.Overlaps {
+		fmt.Fprintf(&buf, "%s & %s: %d prefixes, %s IPv4 addresses, %s IPv6 addresses\n",
+			overlap.Lists[0], overlap.Lists[1], len(overlap.Prefixes), overlap.IPv4AddressCount, overlap.IPv6AddressCount)
+		for _, prefix := range overlap.Prefixes {
+			fmt.Fprintf(&buf, "  %s\n", prefix)
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,185 @@
+package special
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+func TestOverlapReportOutput(t *testing.T) {
+	container, err := lib.NewContainerFromMap(map[string][]string{
+		"cn":         {"1.0.0.0/16", "2001:250::/32"},
+		"cloudflare": {"1.0.0.0/24", "2001:250::/48"},
+		"au":         {"1.0.0.0/23"},
+		"hk":         {"2.0.0.0/16"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		args       string
+		lists      []string
+		ipv4Matrix string
+		ipv6Matrix string
+		overlaps   []string
+	}{
+		{
+			name:       "all lists",
+			args:       `{}`,
+			lists:      []string{"AU", "CLOUDFLARE", "CN", "HK"},
+			ipv4Matrix: "[[512 256 512 0] [256 256 256 0] [512 256 65536 0] [0 0 0 65536]]",
+			ipv6Matrix: "[[0 0 0 0] [0 1208925819614629174706176 1208925819614629174706176 0] [0 1208925819614629174706176 79228162514264337593543950336 0] [0 0 0 0]]",
+			overlaps: []string{
+				"AU & CLOUDFLARE: 256 0 [1.0.0.0/24]",
+				"AU & CN: 512 0 [1.0.0.0/23]",
+				"CLOUDFLARE & CN: 256 1208925819614629174706176 [1.0.0.0/24 2001:250::/48]",
+			},
+		},
+		{
+			name:       "wanted and excluded lists",
+			args:       `{"wantedList": ["cn", "cloudflare", "au"], "excludedList": ["AU"]}`,
+			lists:      []string{"CLOUDFLARE", "CN"},
+			ipv4Matrix: "[[256 256] [256 65536]]",
+			ipv6Matrix: "[[1208925819614629174706176 1208925819614629174706176] [1208925819614629174706176 79228162514264337593543950336]]",
+			overlaps:   []string{"CLOUDFLARE & CN: 256 1208925819614629174706176 [1.0.0.0/24 2001:250::/48]"},
+		},
+		{
+			name:       "only ipv6",
+			args:       `{"excludedList": ["hk"], "onlyIPType": "ipv6"}`,
+			lists:      []string{"AU", "CLOUDFLARE", "CN"},
+			ipv4Matrix: "[[0 0 0] [0 0 0] [0 0 0]]",
+			ipv6Matrix: "[[0 0 0] [0 1208925819614629174706176 1208925819614629174706176] [0 1208925819614629174706176 79228162514264337593543950336]]",
+			overlaps:   []string{"CLOUDFLARE & CN: 0 1208925819614629174706176 [2001:250::/48]"},
+		},
+		{
+			name:       "missing wanted list",
+			args:       `{"wantedList": ["hk", "us"]}`,
+			lists:      []string{"HK"},
+			ipv4Matrix: "[[65536]]",
+			ipv6Matrix: "[[0]]",
+			overlaps:   []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(tt.args), &args); err != nil {
+				t.Fatal(err)
+			}
+			args["outputDir"] = t.TempDir()
+
+			converter, err := newOverlapReport(lib.ActionOutput, must(json.Marshal(args)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := converter.Output(container); err != nil {
+				t.Fatalf("Output(%s) error = %v", tt.args, err)
+			}
+
+			var result overlapResult
+			if err := json.Unmarshal(must(os.ReadFile(filepath.Join(args["outputDir"].(string), "overlap.json"))), &result); err != nil {
+				t.Fatal(err)
+			}
+
+			if !slices.Equal(result.Lists, tt.lists) {
+				t.Errorf("lists = %v, want %v", result.Lists, tt.lists)
+			}
+			if got := fmt.Sprint(result.IPv4Matrix); got != tt.ipv4Matrix {
+				t.Errorf("ipv4Matrix = %s, want %s", got, tt.ipv4Matrix)
+			}
+			if got := fmt.Sprint(result.IPv6Matrix); got != tt.ipv6Matrix {
+				t.Errorf("ipv6Matrix = %s, want %s", got, tt.ipv6Matrix)
+			}
+
+			overlaps := make([]string, 0, len(result.Overlaps))
+			for _, overlap := range result.Overlaps {
+				overlaps = append(overlaps, fmt.Sprintf("%s & %s: %s %s %v", overlap.Lists[0], overlap.Lists[1], overlap.IPv4AddressCount, overlap.IPv6AddressCount, overlap.Prefixes))
+			}
+			if !slices.Equal(overlaps, tt.overlaps) {
+				t.Errorf("overlaps = %q, want %q", overlaps, tt.overlaps)
+			}
+		})
+	}
+}
+
+func TestOverlapReportOutputText(t *testing.T) {
+	container, err := lib.NewContainerFromMap(map[string][]string{
+		"cn": {"1.0.0.0/16"},
+		"au": {"1.0.0.0/23"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	converter, err := newOverlapReport(lib.ActionOutput, must(json.Marshal(map[string]any{"format": " TEXT ", "outputDir": dir})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := converter.Output(container); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `IPv4 addresses shared by lists:
+       AU     CN
+  AU  512    512
+  CN  512  65536
+
+IPv6 addresses shared by lists:
+      AU  CN
+  AU   0   0
+  CN   0   0
+
+AU & CN: 1 prefixes, 512 IPv4 addresses, 0 IPv6 addresses
+  1.0.0.0/23
+
+`
+	if got := string(must(os.ReadFile(filepath.Join(dir, "overlap.txt")))); got != want {
+		t.Errorf("report = %q, want %q", got, want)
+	}
+}
+
+func TestNewOverlapReportFormat(t *testing.T) {
+	tests := []struct {
+		args       string
+		outputName string
+		wantErr    string
+	}{
+		{args: `{}`, outputName: "overlap.json"},
+		{args: `{"format": "text"}`, outputName: "overlap.txt"},
+		{args: `{"format": "text", "outputName": "report.txt"}`, outputName: "report.txt"},
+		{args: `{"format": "csv"}`, wantErr: "invalid format csv"},
+	}
+
+	for _, tt := range tests {
+		converter, err := newOverlapReport(lib.ActionOutput, json.RawMessage(tt.args))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("newOverlapReport(%s) error = %v, want error containing %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("newOverlapReport(%s) error = %v", tt.args, err)
+		}
+		if got := converter.(*OverlapReport).OutputName; got != tt.outputName {
+			t.Errorf("newOverlapReport(%s) outputName = %s, want %s", tt.args, got, tt.outputName)
+		}
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
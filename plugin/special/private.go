@@ -43,6 +43,19 @@ func init() {
 	lib.RegisterInputConverter(TypePrivate, &Private{
 		Description: DescPrivate,
 	})
+	lib.RegisterInputConverterSchema(TypePrivate, newPrivateSchema())
+}
+
+func newPrivateSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: []*lib.Arg{
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"onlyIPType": lib.IPv4,
+		},
+	}
 }
 
 func newPrivate(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -83,6 +96,10 @@ func (p *Private) GetDescription() string {
 	return p.Description
 }
 
+func (p *Private) ResolvedArgs() map[string]any {
+	return lib.ListArgs(nil, nil, p.OnlyIPType)
+}
+
 func (p *Private) Input(container lib.Container) (lib.Container, error) {
 	entry, found := container.GetEntry(entryNamePrivate)
 	if !found {
@@ -0,0 +1,320 @@
+package special
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"go4.org/netipx"
+)
+
+const (
+	TypeSetOperation = "setOperation"
+	DescSetOperation = "Create list from intersection, union or difference of lists from previous steps"
+)
+
+func init() {
+	lib.RegisterInputConfigCreator(TypeSetOperation, func(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+		return newSetOperation(action, data)
+	})
+	lib.RegisterInputConverter(TypeSetOperation, &SetOperation{
+		Description: DescSetOperation,
+	})
+	lib.RegisterInputConverterSchema(TypeSetOperation, newSetOperationSchema())
+}
+
+func newSetOperationSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: []*lib.Arg{
+			{
+				Name:        "name",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the list to write the result to",
+				Required:    true,
+			},
+			{
+				Name:        "expression",
+				Type:        lib.ArgTypeString,
+				Description: "Set expression of lists, with operators |, &, - and parentheses",
+				Required:    true,
+			},
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"name":       "cn-cloudflare",
+			"expression": "cn & cloudflare",
+		},
+	}
+}
+
+func newSetOperation(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
+	var tmp struct {
+		Name       string     `json:"name"`
+		Expression string     `json:"expression"`
+		OnlyIPType lib.IPType `json:"onlyIPType"`
+	}
+
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &tmp); err != nil {
+			return nil, err
+		}
+	}
+
+	tmp.Name = strings.TrimSpace(tmp.Name)
+	if tmp.Name == "" {
+		return nil, fmt.Errorf("❌ [type %s | action %s] missing name", TypeSetOperation, action)
+	}
+
+	tmp.Expression = strings.TrimSpace(tmp.Expression)
+	if tmp.Expression == "" {
+		return nil, fmt.Errorf("❌ [type %s | action %s] missing expression", TypeSetOperation, action)
+	}
+
+	// Parse expression in advance to report syntax errors early
+	if _, err := parseSetExpression(tmp.Expression); err != nil {
+		return nil, fmt.Errorf("❌ [type %s | action %s] invalid expression %q: %v", TypeSetOperation, action, tmp.Expression, err)
+	}
+
+	return &SetOperation{
+		Type:        TypeSetOperation,
+		Action:      action,
+		Description: DescSetOperation,
+		Name:        tmp.Name,
+		Expression:  tmp.Expression,
+		OnlyIPType:  tmp.OnlyIPType,
+	}, nil
+}
+
+type SetOperation struct {
+	Type        string
+	Action      lib.Action
+	Description string
+	Name        string
+	Expression  string
+	OnlyIPType  lib.IPType
+}
+
+func (s *SetOperation) GetType() string {
+	return s.Type
+}
+
+func (s *SetOperation) GetAction() lib.Action {
+	return s.Action
+}
+
+func (s *SetOperation) GetDescription() string {
+	return s.Description
+}
+
+func (s *SetOperation) ResolvedArgs() map[string]any {
+	return lib.ListArgs(nil, nil, s.OnlyIPType)
+}
+
+func (s *SetOperation) Input(container lib.Container) (lib.Container, error) {
+	expr, err := parseSetExpression(s.Expression)
+	if err != nil {
+		return nil, fmt.Errorf("❌ [type %s | action %s] invalid expression %q: %v", s.Type, s.Action, s.Expression, err)
+	}
+
+	ipset, err := expr.eval(container)
+	if err != nil {
+		return nil, fmt.Errorf("❌ [type %s | action %s] %v", s.Type, s.Action, err)
+	}
+
+	prefixes := ipset.Prefixes()
+	if len(prefixes) == 0 {
+		// Not an error, as lists of upstream data may not intersect at times
+		log.Printf("[type %s | action %s] result of expression %q is empty, list %s is not generated\n", s.Type, s.Action, s.Expression, strings.ToUpper(s.Name))
+		return container, nil
+	}
+
+	entry := lib.NewEntry(s.Name)
+	for _, prefix := range prefixes {
+		if err := entry.AddPrefix(prefix); err != nil {
+			return nil, err
+		}
+	}
+
+	ignoreIPType := lib.GetIgnoreIPType(s.OnlyIPType)
+
+	switch s.Action {
+	case lib.ActionAdd:
+		if err := container.Add(entry, ignoreIPType); err != nil {
+			return nil, err
+		}
+	case lib.ActionRemove:
+		if err := container.Remove(entry, lib.CaseRemovePrefix, ignoreIPType); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, lib.ErrUnknownAction
+	}
+
+	return container, nil
+}
+
+// setExpression is a node of the parsed set operation expression.
+// A leaf node refers to a list name, an inner node applies op to left and right.
+type setExpression struct {
+	name  string
+	op    byte
+	left  *setExpression
+	right *setExpression
+}
+
+func (e *setExpression) eval(container lib.Container) (*netipx.IPSet, error) {
+	if e.op == 0 {
+		entry, found := container.GetEntry(e.name)
+		if !found {
+			return nil, fmt.Errorf("list %s not found", e.name)
+		}
+
+		return entry.GetIPSet()
+	}
+
+	left, err := e.left.eval(container)
+	if err != nil {
+		return nil, err
+	}
+	right, err := e.right.eval(container)
+	if err != nil {
+		return nil, err
+	}
+
+	var builder netipx.IPSetBuilder
+	builder.AddSet(left)
+	switch e.op {
+	case '&':
+		builder.Intersect(right)
+	case '|':
+		builder.AddSet(right)
+	case '-':
+		builder.RemoveSet(right)
+	}
+
+	return builder.IPSet()
+}
+
+// parseSetExpression parses expressions like `CN & CLOUDFLARE`, `GOOGLE | TELEGRAM`
+// and `(CN | HK) - PRIVATE`. The `&` operator binds tighter than `|` and `-`,
+// which are evaluated from left to right. As list names may contain hyphens,
+// the `-` operator must be separated from list names by whitespace.
+func parseSetExpression(expression string) (*setExpression, error) {
+	p := &setExpressionParser{tokens: tokenizeSetExpression(expression)}
+	if len(p.tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	expr, err := p.parseUnion()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+
+	return expr, nil
+}
+
+func tokenizeSetExpression(expression string) []string {
+	tokens := make([]string, 0, 8)
+	var name strings.Builder
+	flush := func() {
+		if name.Len() > 0 {
+			tokens = append(tokens, name.String())
+			name.Reset()
+		}
+	}
+
+	for _, r := range expression {
+		switch r {
+		case ' ', '\t', '\r', '\n':
+			flush()
+		case '&', '|', '(', ')':
+			flush()
+			tokens = append(tokens, string(r))
+		default:
+			name.WriteRune(r)
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+type setExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *setExpressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *setExpressionParser) parseUnion() (*setExpression, error) {
+	left, err := p.parseIntersection()
+	if err != nil {
+		return nil, err
+	}
+
+	for token := p.peek(); token == "|" || token == "-"; token = p.peek() {
+		p.pos++
+		right, err := p.parseIntersection()
+		if err != nil {
+			return nil, err
+		}
+		left = &setExpression{op: token[0], left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *setExpressionParser) parseIntersection() (*setExpression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.peek() == "&" {
+		p.pos++
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		left = &setExpression{op: '&', left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *setExpressionParser) parseOperand() (*setExpression, error) {
+	token := p.peek()
+	switch token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	case "(":
+		p.pos++
+		expr, err := p.parseUnion()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+
+	case ")", "&", "|", "-":
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+
+	p.pos++
+	return &setExpression{name: strings.ToUpper(token)}, nil
+}
@@ -0,0 +1,137 @@
+package special
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+// format returns the expression with every operation parenthesized.
+func (e *setExpression) format() string {
+	if e.op == 0 {
+		return e.name
+	}
+	return "(" + e.left.format() + " " + string(e.op) + " " + e.right.format() + ")"
+}
+
+func TestParseSetExpression(t *testing.T) {
+	tests := []struct {
+		expression string
+		want       string
+	}{
+		{expression: "cn", want: "CN"},
+		{expression: "CN & CLOUDFLARE", want: "(CN & CLOUDFLARE)"},
+		{expression: "cn&cloudflare", want: "(CN & CLOUDFLARE)"},
+		{expression: "google | telegram", want: "(GOOGLE | TELEGRAM)"},
+		{expression: "(cn | hk) - private", want: "((CN | HK) - PRIVATE)"},
+		{expression: "a | b & c", want: "(A | (B & C))"},
+		{expression: "a & b | c", want: "((A & B) | C)"},
+		{expression: "a - b | c", want: "((A - B) | C)"},
+		{expression: "a | b - c", want: "((A | B) - C)"},
+		{expression: "a - (b | c)", want: "(A - (B | C))"},
+		{expression: "geolocation-cn - private", want: "(GEOLOCATION-CN - PRIVATE)"},
+		{expression: " ((cn))\n&\thk ", want: "(CN & HK)"},
+	}
+
+	for _, tt := range tests {
+		expr, err := parseSetExpression(tt.expression)
+		if err != nil {
+			t.Errorf("parseSetExpression(%q) error = %v", tt.expression, err)
+			continue
+		}
+		if got := expr.format(); got != tt.want {
+			t.Errorf("parseSetExpression(%q) = %s, want %s", tt.expression, got, tt.want)
+		}
+	}
+}
+
+func TestParseSetExpressionError(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{expression: "", wantErr: "empty expression"},
+		{expression: "  ", wantErr: "empty expression"},
+		{expression: "cn &", wantErr: "unexpected end of expression"},
+		{expression: "| cn", wantErr: `unexpected "|"`},
+		{expression: "cn & & hk", wantErr: `unexpected "&"`},
+		{expression: "- cn", wantErr: `unexpected "-"`},
+		{expression: "(cn | hk", wantErr: "missing closing parenthesis"},
+		{expression: "cn | hk)", wantErr: `unexpected ")"`},
+		{expression: "()", wantErr: `unexpected ")"`},
+		{expression: "cn hk", wantErr: `unexpected "hk"`},
+	}
+
+	for _, tt := range tests {
+		_, err := parseSetExpression(tt.expression)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("parseSetExpression(%q) error = %v, want error containing %q", tt.expression, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSetOperationInput(t *testing.T) {
+	newContainer := func(t *testing.T) lib.Container {
+		t.Helper()
+		container, err := lib.NewContainerFromMap(map[string][]string{
+			"cn":         {"1.0.0.0/16", "2001:250::/32"},
+			"hk":         {"2.0.0.0/16"},
+			"cloudflare": {"1.0.0.0/24", "3.0.0.0/24"},
+			"private":    {"1.0.128.0/17"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return container
+	}
+
+	tests := []struct {
+		args    string
+		want    []string
+		wantErr string
+	}{
+		{args: `{"name": "result", "expression": "cn & cloudflare"}`, want: []string{"1.0.0.0/24"}},
+		{args: `{"name": "result", "expression": "(cn | hk) - private"}`, want: []string{"1.0.0.0/17", "2.0.0.0/16", "2001:250::/32"}},
+		{args: `{"name": "result", "expression": "cn - private", "onlyIPType": "ipv4"}`, want: []string{"1.0.0.0/17"}},
+		{args: `{"name": "result", "expression": "hk & cloudflare"}`},
+		{args: `{"name": "result", "expression": "cn & nope"}`, wantErr: "list NOPE not found"},
+	}
+
+	for _, tt := range tests {
+		converter, err := newSetOperation(lib.ActionAdd, json.RawMessage(tt.args))
+		if err != nil {
+			t.Fatalf("newSetOperation(%s) error = %v", tt.args, err)
+		}
+
+		container, err := converter.Input(newContainer(t))
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Input(%s) error = %v, want error containing %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Input(%s) error = %v", tt.args, err)
+		}
+
+		got := make([]string, 0)
+		if entry, found := container.GetEntry("result"); found {
+			if got, err = entry.MarshalText(); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if !slices.Equal(got, tt.want) && !(len(got) == 0 && len(tt.want) == 0) {
+			t.Errorf("Input(%s) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestNewSetOperationInvalidExpression(t *testing.T) {
+	_, err := newSetOperation(lib.ActionAdd, json.RawMessage(`{"name": "result", "expression": "cn &"}`))
+	if err == nil || !strings.Contains(err.Error(), "invalid expression") {
+		t.Errorf("newSetOperation() error = %v, want invalid expression error", err)
+	}
+}
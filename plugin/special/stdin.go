@@ -22,6 +22,25 @@ func init() {
 	lib.RegisterInputConverter(TypeStdin, &Stdin{
 		Description: DescStdin,
 	})
+	lib.RegisterInputConverterSchema(TypeStdin, newStdinSchema())
+}
+
+func newStdinSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: []*lib.Arg{
+			{
+				Name:        "name",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the list",
+				Required:    true,
+			},
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"name": "cn",
+		},
+	}
 }
 
 func newStdin(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -69,6 +88,10 @@ func (s *Stdin) GetDescription() string {
 	return s.Description
 }
 
+func (s *Stdin) ResolvedArgs() map[string]any {
+	return lib.ListArgs(nil, nil, s.OnlyIPType)
+}
+
 func (s *Stdin) Input(container lib.Container) (lib.Container, error) {
 	entry := lib.NewEntry(s.Name)
 
@@ -23,6 +23,21 @@ func init() {
 	lib.RegisterOutputConverter(TypeStdout, &Stdout{
 		Description: DescStdout,
 	})
+	lib.RegisterOutputConverterSchema(TypeStdout, newStdoutSchema())
+}
+
+func newStdoutSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			lib.ArgWantedList(),
+			lib.ArgExcludedList(),
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"wantedList": []string{"cn", "private"},
+		},
+	}
 }
 
 func newStdout(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
@@ -69,6 +84,14 @@ func (s *Stdout) GetDescription() string {
 	return s.Description
 }
 
+func (s *Stdout) ResolvedArgs() map[string]any {
+	return lib.ListArgs(s.Want, s.Exclude, s.OnlyIPType)
+}
+
+func (s *Stdout) OutputsToStdout() bool {
+	return true
+}
+
 func (s *Stdout) Output(container lib.Container) error {
 	for _, name := range s.filterAndSortList(container) {
 		entry, found := container.GetEntry(name)
@@ -23,6 +23,10 @@ func init() {
 	lib.RegisterInputConverter(typeTest, &test{
 		Description: descTest,
 	})
+	lib.RegisterInputConverterSchema(typeTest, &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args:    []*lib.Arg{},
+	})
 }
 
 func newTest(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -4,9 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net"
-	"net/http"
-	"os"
+	"slices"
 	"strings"
 
 	"github.com/Loyalsoldier/geoip/lib"
@@ -25,6 +25,32 @@ func init() {
 	lib.RegisterInputConverter(TypeGeoIPDatIn, &GeoIPDatIn{
 		Description: DescGeoIPDatIn,
 	})
+	lib.RegisterInputConverterSchema(TypeGeoIPDatIn, newGeoIPDatInSchema())
+}
+
+func newGeoIPDatInSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionAdd, lib.ActionRemove},
+		Args: append([]*lib.Arg{
+			{
+				Name:        "uri",
+				Type:        lib.ArgTypeString,
+				Description: "Path or URL of the geoip.dat file",
+				Required:    true,
+			},
+			{
+				Name:        "wantedList",
+				Type:        lib.ArgTypeStringList,
+				Description: "The only lists to read",
+			},
+			lib.ArgOnlyIPType(),
+			lib.ArgHTTP(),
+		}, lib.ArgsChecksum()...),
+		Example: map[string]any{
+			"uri":        "./geoip.dat",
+			"wantedList": []string{"cn", "private"},
+		},
+	}
 }
 
 func newGeoIPDatIn(action lib.Action, data json.RawMessage) (lib.InputConverter, error) {
@@ -32,6 +58,9 @@ func newGeoIPDatIn(action lib.Action, data json.RawMessage) (lib.InputConverter,
 		URI        string     `json:"uri"`
 		Want       []string   `json:"wantedList"`
 		OnlyIPType lib.IPType `json:"onlyIPType"`
+
+		HTTP *lib.HTTPConfig `json:"http"`
+		lib.Checksum
 	}
 
 	if len(data) > 0 {
@@ -59,6 +88,9 @@ func newGeoIPDatIn(action lib.Action, data json.RawMessage) (lib.InputConverter,
 		URI:         tmp.URI,
 		Want:        wantList,
 		OnlyIPType:  tmp.OnlyIPType,
+
+		HTTP:     tmp.HTTP,
+		Checksum: tmp.Checksum,
 	}, nil
 }
 
@@ -69,6 +101,11 @@ type GeoIPDatIn struct {
 	URI         string
 	Want        map[string]bool
 	OnlyIPType  lib.IPType
+
+	HTTP     *lib.HTTPConfig
+	Checksum lib.Checksum
+
+	prefetched map[string]*lib.Entry
 }
 
 func (g *GeoIPDatIn) GetType() string {
@@ -83,23 +120,30 @@ func (g *GeoIPDatIn) GetDescription() string {
 	return g.Description
 }
 
-func (g *GeoIPDatIn) Input(container lib.Container) (lib.Container, error) {
-	entries := make(map[string]*lib.Entry)
-	var err error
-
-	switch {
-	case strings.HasPrefix(strings.ToLower(g.URI), "http://"), strings.HasPrefix(strings.ToLower(g.URI), "https://"):
-		err = g.walkRemoteFile(g.URI, entries)
-	default:
-		err = g.walkLocalFile(g.URI, entries)
-	}
+func (g *GeoIPDatIn) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(slices.Collect(maps.Keys(g.Want)), nil, g.OnlyIPType)
+	args["uri"] = g.URI
+	return args
+}
 
+func (g *GeoIPDatIn) Prefetch() error {
+	entries, err := g.loadEntries()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if len(entries) == 0 {
-		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	g.prefetched = entries
+	return nil
+}
+
+func (g *GeoIPDatIn) Input(container lib.Container) (lib.Container, error) {
+	entries := g.prefetched
+	g.prefetched = nil
+	if entries == nil {
+		var err error
+		if entries, err = g.loadEntries(); err != nil {
+			return nil, err
+		}
 	}
 
 	ignoreIPType := lib.GetIgnoreIPType(g.OnlyIPType)
@@ -122,8 +166,30 @@ func (g *GeoIPDatIn) Input(container lib.Container) (lib.Container, error) {
 	return container, nil
 }
 
+func (g *GeoIPDatIn) loadEntries() (map[string]*lib.Entry, error) {
+	entries := make(map[string]*lib.Entry)
+	var err error
+
+	switch {
+	case strings.HasPrefix(strings.ToLower(g.URI), "http://"), strings.HasPrefix(strings.ToLower(g.URI), "https://"):
+		err = g.walkRemoteFile(g.URI, entries)
+	default:
+		err = g.walkLocalFile(g.URI, entries)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("❌ [type %s | action %s] no entry is generated", g.Type, g.Action)
+	}
+
+	return entries, nil
+}
+
 func (g *GeoIPDatIn) walkLocalFile(path string, entries map[string]*lib.Entry) error {
-	file, err := os.Open(path)
+	file, err := lib.OpenURI(path, &g.Checksum, g.HTTP)
 	if err != nil {
 		return err
 	}
@@ -137,17 +203,13 @@ func (g *GeoIPDatIn) walkLocalFile(path string, entries map[string]*lib.Entry) e
 }
 
 func (g *GeoIPDatIn) walkRemoteFile(url string, entries map[string]*lib.Entry) error {
-	resp, err := http.Get(url)
+	body, err := lib.OpenURI(url, &g.Checksum, g.HTTP)
 	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("❌ [type %s | action %s] failed to get remote file %s, http status code %d", g.Type, g.Action, url, resp.StatusCode)
+		return fmt.Errorf("❌ [type %s | action %s] %v", g.Type, g.Action, err)
 	}
+	defer body.Close()
 
-	if err := g.generateEntries(resp.Body, entries); err != nil {
+	if err := g.generateEntries(body, entries); err != nil {
 		return err
 	}
 
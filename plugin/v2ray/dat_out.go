@@ -31,6 +31,40 @@ func init() {
 	lib.RegisterOutputConverter(TypeGeoIPDatOut, &GeoIPDatOut{
 		Description: DescGeoIPDatOut,
 	})
+	lib.RegisterOutputConverterSchema(TypeGeoIPDatOut, newGeoIPDatOutSchema())
+}
+
+func newGeoIPDatOutSchema() *lib.ConverterSchema {
+	return &lib.ConverterSchema{
+		Actions: []lib.Action{lib.ActionOutput},
+		Args: []*lib.Arg{
+			{
+				Name:        "outputName",
+				Type:        lib.ArgTypeString,
+				Description: "Name of the output file, ignored if oneFilePerList is true",
+				Default:     defaultOutputName,
+			},
+			{
+				Name:        "outputDir",
+				Type:        lib.ArgTypeString,
+				Description: "Directory to write files to",
+				Default:     defaultOutputDir,
+			},
+			lib.ArgWantedList(),
+			lib.ArgExcludedList(),
+			{
+				Name:        "oneFilePerList",
+				Type:        lib.ArgTypeBoolean,
+				Description: "Write each list to a separate file",
+				Default:     false,
+			},
+			lib.ArgOnlyIPType(),
+		},
+		Example: map[string]any{
+			"outputName": "geoip-only-cn-private.dat",
+			"wantedList": []string{"cn", "private"},
+		},
+	}
 }
 
 func newGeoIPDatOut(action lib.Action, data json.RawMessage) (lib.OutputConverter, error) {
@@ -94,7 +128,37 @@ func (g *GeoIPDatOut) GetDescription() string {
 	return g.Description
 }
 
+func (g *GeoIPDatOut) ResolvedArgs() map[string]any {
+	args := lib.ListArgs(g.Want, g.Exclude, g.OnlyIPType)
+	args["outputName"] = g.OutputName
+	args["outputDir"] = g.OutputDir
+	return args
+}
+
 func (g *GeoIPDatOut) Output(container lib.Container) error {
+	if g.OneFilePerList {
+		return lib.ForEachConcurrently(g.filterAndSortList(container), func(name string) error {
+			entry, found := container.GetEntry(name)
+			if !found {
+				log.Printf("❌ entry %s not found\n", name)
+				return nil
+			}
+
+			geoIP, err := g.generateGeoIP(entry)
+			if err != nil {
+				return err
+			}
+
+			geoIPBytes, err := proto.Marshal(&GeoIPList{Entry: []*GeoIP{geoIP}})
+			if err != nil {
+				return err
+			}
+
+			filename := strings.ToLower(entry.GetName()) + ".dat"
+			return g.writeFile(filename, geoIPBytes)
+		})
+	}
+
 	geoIPList := new(GeoIPList)
 	geoIPList.Entry = make([]*GeoIP, 0, 300)
 	updated := false
@@ -112,23 +176,9 @@ func (g *GeoIPDatOut) Output(container lib.Container) error {
 		}
 		geoIPList.Entry = append(geoIPList.Entry, geoIP)
 		updated = true
-
-		if g.OneFilePerList {
-			geoIPBytes, err := proto.Marshal(geoIPList)
-			if err != nil {
-				return err
-			}
-
-			filename := strings.ToLower(entry.GetName()) + ".dat"
-			if err := g.writeFile(filename, geoIPBytes); err != nil {
-				return err
-			}
-
-			geoIPList.Entry = nil
-		}
 	}
 
-	if !g.OneFilePerList && updated {
+	if updated {
 		// Sort to make reproducible builds
 		g.sort(geoIPList)
 
@@ -211,6 +261,13 @@ func (g *GeoIPDatOut) sort(list *GeoIPList) {
 	})
 }
 
+func (g *GeoIPDatOut) OutputFiles() []string {
+	if g.OneFilePerList {
+		return lib.ListFiles(g.OutputDir, ".dat", g.Want, g.Exclude)
+	}
+	return []string{filepath.Join(g.OutputDir, g.OutputName)}
+}
+
 func (g *GeoIPDatOut) writeFile(filename string, geoIPBytes []byte) error {
 	if err := os.MkdirAll(g.OutputDir, 0755); err != nil {
 		return err
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+	schemaCmd.Flags().StringP("output", "o", "", "The file to write the JSON Schema to (default is stdout)")
+}
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Print the JSON Schema of config file for editors to autocomplete and validate",
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		content, err := json.MarshalIndent(lib.JSONSchema(), "", "  ")
+		if err != nil {
+			log.Fatal(err)
+		}
+		content = append(content, '\n')
+
+		if output == "" {
+			os.Stdout.Write(content)
+			return
+		}
+
+		if err := os.WriteFile(output, content, 0644); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("✅ JSON Schema has been written to %s\n", output)
+	},
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"github.com/spf13/cobra"
+)
+
+// The maximum size of the request body of batch lookup
+const maxBatchLookupBodySize = 10 << 20
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	addInputFlags(serveCmd)
+	serveCmd.Flags().StringP("listen", "a", "127.0.0.1:8080", "The address to listen on")
+}
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API to lookup IP or CIDR in lists",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		source := getInputSource(cmd, defaultListName)
+		listen, _ := cmd.Flags().GetString("listen")
+
+		container, err := loadFrozenContainer(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		server := &http.Server{
+			Addr:              listen,
+			Handler:           newLookupHandler(container),
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		go func() {
+			<-ctx.Done()
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+			server.Shutdown(shutdownCtx)
+		}()
+
+		log.Printf("Serving %d lists on %s\n", container.Len(), listen)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal(err)
+		}
+	},
+}
+
+type lookupResponse struct {
+	IP    string   `json:"ip"`
+	Found bool     `json:"found"`
+	Lists []string `json:"lists"`
+	Error string   `json:"error,omitempty"`
+}
+
+type batchLookupRequest struct {
+	IPs   []string `json:"ips"`
+	Lists []string `json:"lists"`
+}
+
+type batchLookupResponse struct {
+	Results []*lookupResponse `json:"results"`
+}
+
+// newLookupHandler returns the handler of lookup API:
+//
+//	GET  /lookup?ip=1.1.1.1&list=cn,us  lookup an IP or CIDR
+//	POST /lookup                        lookup IPs or CIDRs in batch, with body {"ips": [], "lists": []}
+//	GET  /healthz                       health check
+func newLookupHandler(container lib.Container) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /lookup", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		search := strings.TrimSpace(query.Get("ip"))
+		if search == "" {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "missing ip"})
+			return
+		}
+
+		searchList := make([]string, 0, len(query["list"]))
+		for _, list := range query["list"] {
+			searchList = append(searchList, strings.Split(list, ",")...)
+		}
+
+		result := newLookupResponse(container, search, searchList)
+		if result.Error != "" {
+			writeJSON(w, http.StatusBadRequest, result)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+
+	mux.HandleFunc("POST /lookup", func(w http.ResponseWriter, r *http.Request) {
+		var req batchLookupRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchLookupBodySize)).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid request body: " + err.Error()})
+			return
+		}
+
+		resp := &batchLookupResponse{
+			Results: make([]*lookupResponse, 0, len(req.IPs)),
+		}
+		for _, search := range req.IPs {
+			resp.Results = append(resp.Results, newLookupResponse(container, strings.TrimSpace(search), req.Lists))
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"status": "ok",
+			"lists":  container.Len(),
+		})
+	})
+
+	return mux
+}
+
+func newLookupResponse(container lib.Container, search string, searchList []string) *lookupResponse {
+	result := &lookupResponse{
+		IP:    search,
+		Lists: []string{},
+	}
+
+	if !isValidIPOrCIDR(strings.ToLower(search)) {
+		result.Error = "invalid IP or CIDR"
+		return result
+	}
+
+	lists, found, err := container.Lookup(search, searchList...)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	for _, list := range lists {
+		result.Lists = append(result.Lists, strings.ToLower(list))
+	}
+	slices.Sort(result.Lists)
+	result.Found = found
+
+	return result
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
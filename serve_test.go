@@ -0,0 +1,197 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/Loyalsoldier/geoip/lib"
+)
+
+func newTestLookupServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	container, err := lib.NewContainerFromMap(map[string][]string{
+		"cn":         {"1.0.1.0/24", "2001:250::/35"},
+		"au":         {"1.0.0.0/24"},
+		"cloudflare": {"1.0.0.0/24", "1.1.1.0/24"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := container.Freeze(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(newLookupHandler(container))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func decodeResponse[T any](t *testing.T, resp *http.Response) T {
+	t.Helper()
+	defer resp.Body.Close()
+
+	if contentType := resp.Header.Get("Content-Type"); contentType != "application/json" {
+		t.Errorf("Content-Type = %s, want application/json", contentType)
+	}
+
+	var v T
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestLookupHandlerGet(t *testing.T) {
+	server := newTestLookupServer(t)
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantFound  bool
+		wantLists  []string
+		wantError  bool
+	}{
+		{name: "hit", query: "ip=1.0.0.1", wantStatus: http.StatusOK, wantFound: true, wantLists: []string{"au", "cloudflare"}},
+		{name: "hit ipv6", query: "ip=2001:250::1", wantStatus: http.StatusOK, wantFound: true, wantLists: []string{"cn"}},
+		{name: "hit cidr", query: "ip=1.0.1.0/25", wantStatus: http.StatusOK, wantFound: true, wantLists: []string{"cn"}},
+		{name: "miss", query: "ip=8.8.8.8", wantStatus: http.StatusOK, wantLists: []string{}},
+		{name: "list filter", query: "ip=1.0.0.1&list=cloudflare", wantStatus: http.StatusOK, wantFound: true, wantLists: []string{"cloudflare"}},
+		{name: "list filter separated by comma", query: "ip=1.0.0.1&list=CN,au", wantStatus: http.StatusOK, wantFound: true, wantLists: []string{"au"}},
+		{name: "list filter repeated", query: "ip=1.0.0.1&list=cn&list=au", wantStatus: http.StatusOK, wantFound: true, wantLists: []string{"au"}},
+		{name: "list filter miss", query: "ip=1.0.0.1&list=cn", wantStatus: http.StatusOK, wantLists: []string{}},
+		{name: "invalid ip", query: "ip=300.300.300.300", wantStatus: http.StatusBadRequest, wantLists: []string{}, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Get(server.URL + "/lookup?" + tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			result := decodeResponse[lookupResponse](t, resp)
+			if result.Found != tt.wantFound {
+				t.Errorf("found = %v, want %v", result.Found, tt.wantFound)
+			}
+			if !slices.Equal(result.Lists, tt.wantLists) {
+				t.Errorf("lists = %v, want %v", result.Lists, tt.wantLists)
+			}
+			if (result.Error != "") != tt.wantError {
+				t.Errorf("error = %q, want error: %v", result.Error, tt.wantError)
+			}
+		})
+	}
+}
+
+func TestLookupHandlerGetMissingIP(t *testing.T) {
+	server := newTestLookupServer(t)
+
+	resp, err := http.Get(server.URL + "/lookup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	if result := decodeResponse[map[string]string](t, resp); result["error"] == "" {
+		t.Errorf("response = %v, want error", result)
+	}
+}
+
+func TestLookupHandlerPost(t *testing.T) {
+	server := newTestLookupServer(t)
+
+	body := `{"ips": ["1.0.0.1", " 1.1.1.1 ", "8.8.8.8", "foo"], "lists": ["cloudflare", "cn"]}`
+	resp, err := http.Post(server.URL+"/lookup", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	result := decodeResponse[batchLookupResponse](t, resp)
+	want := []lookupResponse{
+		{IP: "1.0.0.1", Found: true, Lists: []string{"cloudflare"}},
+		{IP: "1.1.1.1", Found: true, Lists: []string{"cloudflare"}},
+		{IP: "8.8.8.8", Lists: []string{}},
+		{IP: "foo", Lists: []string{}, Error: "invalid IP or CIDR"},
+	}
+	if len(result.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(result.Results), len(want))
+	}
+	for idx, got := range result.Results {
+		if got.IP != want[idx].IP || got.Found != want[idx].Found || !slices.Equal(got.Lists, want[idx].Lists) || got.Error != want[idx].Error {
+			t.Errorf("result #%d = %+v, want %+v", idx, *got, want[idx])
+		}
+	}
+}
+
+func TestLookupHandlerPostInvalidBody(t *testing.T) {
+	server := newTestLookupServer(t)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid json", body: `{"ips": [`},
+		{name: "too large", body: `{"ips": ["` + strings.Repeat("1", maxBatchLookupBodySize) + `"]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := http.Post(server.URL+"/lookup", "application/json", strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+			}
+			if result := decodeResponse[map[string]string](t, resp); !strings.HasPrefix(result["error"], "invalid request body") {
+				t.Errorf("response = %v, want invalid request body error", result)
+			}
+		})
+	}
+}
+
+func TestLookupHandlerHealthz(t *testing.T) {
+	server := newTestLookupServer(t)
+
+	resp, err := http.Get(server.URL + "/healthz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+
+	result := decodeResponse[map[string]any](t, resp)
+	if result["status"] != "ok" || result["lists"] != float64(3) {
+		t.Errorf("response = %v, want status ok and 3 lists", result)
+	}
+}
+
+func TestLookupHandlerMethodNotAllowed(t *testing.T) {
+	server := newTestLookupServer(t)
+
+	req, err := http.NewRequest(http.MethodDelete, server.URL+"/lookup?ip=1.0.0.1", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(showCmd)
+
+	addInputFlags(showCmd)
+	showCmd.Flags().String("filter", "", "Only print prefixes overlapping the CIDR")
+	showCmd.Flags().Bool("clip", false, "Cut prefixes partially within the CIDR of \"filter\" flag to the part within it")
+	showCmd.Flags().StringP("onlyiptype", "t", "", "The only IP type to print, available options: \"ipv4\", \"ipv6\"")
+	showCmd.Flags().Bool("json", false, "Print the result in JSON format")
+}
+
+var showCmd = &cobra.Command{
+	Use:   "show [list...]",
+	Short: "Print prefixes of specified lists (default is all lists), optionally within a CIDR",
+	Run: func(cmd *cobra.Command, args []string) {
+		source := getInputSource(cmd, defaultListName)
+		filter, _ := cmd.Flags().GetString("filter")
+		clip, _ := cmd.Flags().GetBool("clip")
+		printJSON, _ := cmd.Flags().GetBool("json")
+
+		otype, _ := cmd.Flags().GetString("onlyiptype")
+		otype = strings.ToLower(strings.TrimSpace(otype))
+		if otype != "" && otype != "ipv4" && otype != "ipv6" {
+			log.Fatal("invalid argument onlyiptype: ", otype)
+		}
+
+		var filterPrefix netip.Prefix
+		if filter = strings.TrimSpace(filter); filter != "" {
+			prefix, err := netip.ParsePrefix(filter)
+			if err != nil {
+				log.Fatal("invalid argument filter: ", filter)
+			}
+			filterPrefix = prefix.Masked()
+		} else if clip {
+			log.Fatal(`"clip" flag must be used with "filter" flag`)
+		}
+
+		container, err := loadContainer(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		names := make([]string, 0, len(args))
+		for _, name := range args {
+			if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			for entry := range container.Loop() {
+				names = append(names, entry.GetName())
+			}
+		}
+		slices.Sort(names)
+		names = slices.Compact(names)
+
+		result := make([]*listPrefixes, 0, len(names))
+		for _, name := range names {
+			entry, found := container.GetEntry(name)
+			if !found {
+				log.Fatalf("❌ entry %s not found\n", name)
+			}
+
+			prefixes, err := getPrefixesForShow(entry, filterPrefix, clip, lib.GetIgnoreIPType(lib.IPType(otype)))
+			if err != nil {
+				log.Fatal(err)
+			}
+
+			result = append(result, &listPrefixes{
+				Name:     name,
+				Prefixes: prefixes,
+			})
+		}
+
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		for _, list := range result {
+			// Keep the output valid as text input format by printing list names as comments
+			if len(result) > 1 {
+				fmt.Printf("# %s\n", strings.ToLower(list.Name))
+			}
+			for _, prefix := range list.Prefixes {
+				fmt.Println(prefix)
+			}
+		}
+	},
+}
+
+type listPrefixes struct {
+	Name     string         `json:"name"`
+	Prefixes []netip.Prefix `json:"prefixes"`
+}
+
+// getPrefixesForShow returns prefixes of the entry overlapping filter if it is valid,
+// which are cut to the parts within filter if clip is true.
+func getPrefixesForShow(entry *lib.Entry, filter netip.Prefix, clip bool, ignoreIPType lib.IgnoreIPOption) ([]netip.Prefix, error) {
+	ipset, err := entry.GetIPSet(ignoreIPType)
+	if err != nil {
+		return nil, err
+	}
+
+	if !filter.IsValid() {
+		return ipset.Prefixes(), nil
+	}
+
+	prefixes := make([]netip.Prefix, 0)
+	for _, prefix := range ipset.Prefixes() {
+		if !prefix.Overlaps(filter) {
+			continue
+		}
+		// Overlapping prefixes contain one another
+		if clip && prefix.Bits() < filter.Bits() {
+			prefix = filter
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
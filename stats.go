@@ -0,0 +1,166 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Loyalsoldier/geoip/lib"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	rootCmd.AddCommand(statsCmd)
+
+	addInputFlags(statsCmd)
+	statsCmd.Flags().StringSliceP("searchlist", "l", []string{}, "The lists to print statistics of, separated by comma (default is all lists)")
+	statsCmd.Flags().IntP("top", "n", 5, "The number of largest blocks to print for each list")
+	statsCmd.Flags().Bool("json", false, "Print the result in JSON format")
+}
+
+var statsCmd = &cobra.Command{
+	Use:     "stats",
+	Aliases: []string{"inspect"},
+	Short:   "Print statistics of each list, like number of prefixes and addresses",
+	Args:    cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		source := getInputSource(cmd, defaultListName)
+		searchList, _ := cmd.Flags().GetStringSlice("searchlist")
+		top, _ := cmd.Flags().GetInt("top")
+		printJSON, _ := cmd.Flags().GetBool("json")
+		if top < 0 {
+			log.Fatal("invalid argument top: ", top)
+		}
+
+		container, err := loadContainer(source)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		wantList := make(map[string]bool)
+		for _, name := range searchList {
+			if name = strings.ToUpper(strings.TrimSpace(name)); name != "" {
+				wantList[name] = true
+			}
+		}
+
+		result := make([]*entryStats, 0, container.Len())
+		for entry := range container.Loop() {
+			if len(wantList) > 0 && !wantList[entry.GetName()] {
+				continue
+			}
+
+			result = append(result, newEntryStats(entry, top))
+		}
+		slices.SortFunc(result, func(a, b *entryStats) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(result); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+
+		for _, stats := range result {
+			stats.print()
+		}
+	},
+}
+
+type entryStats struct {
+	Name string   `json:"name"`
+	IPv4 *ipStats `json:"ipv4"`
+	IPv6 *ipStats `json:"ipv6"`
+}
+
+type ipStats struct {
+	PrefixCount   int            `json:"prefixCount"`
+	AddressCount  *big.Int       `json:"addressCount"`
+	PrefixLengths map[int]int    `json:"prefixLengths"`
+	LargestBlocks []netip.Prefix `json:"largestBlocks"`
+}
+
+func newEntryStats(entry *lib.Entry, top int) *entryStats {
+	stats := &entryStats{Name: entry.GetName()}
+
+	if ipv4Set, err := entry.GetIPv4Set(); err == nil {
+		stats.IPv4 = newIPStats(ipv4Set.Prefixes(), top)
+	} else {
+		stats.IPv4 = newIPStats(nil, top)
+	}
+
+	if ipv6Set, err := entry.GetIPv6Set(); err == nil {
+		stats.IPv6 = newIPStats(ipv6Set.Prefixes(), top)
+	} else {
+		stats.IPv6 = newIPStats(nil, top)
+	}
+
+	return stats
+}
+
+func newIPStats(prefixes []netip.Prefix, top int) *ipStats {
+	stats := &ipStats{
+		PrefixCount:   len(prefixes),
+		AddressCount:  lib.CountAddresses(prefixes),
+		PrefixLengths: make(map[int]int),
+		LargestBlocks: make([]netip.Prefix, 0, top),
+	}
+
+	for _, prefix := range prefixes {
+		stats.PrefixLengths[prefix.Bits()]++
+	}
+
+	// Shorter prefixes are larger blocks
+	largest := slices.Clone(prefixes)
+	slices.SortStableFunc(largest, func(a, b netip.Prefix) int {
+		return a.Bits() - b.Bits()
+	})
+	if top > 0 {
+		stats.LargestBlocks = append(stats.LargestBlocks, largest[:min(top, len(largest))]...)
+	}
+
+	return stats
+}
+
+func (s *entryStats) print() {
+	fmt.Println(s.Name)
+	s.IPv4.print("IPv4")
+	s.IPv6.print("IPv6")
+	fmt.Println()
+}
+
+func (s *ipStats) print(ipType string) {
+	fmt.Printf("  %s: %d prefixes, %s addresses\n", ipType, s.PrefixCount, s.AddressCount)
+	if s.PrefixCount == 0 {
+		return
+	}
+
+	lengths := make([]int, 0, len(s.PrefixLengths))
+	for length := range s.PrefixLengths {
+		lengths = append(lengths, length)
+	}
+	slices.Sort(lengths)
+
+	histogram := make([]string, 0, len(lengths))
+	for _, length := range lengths {
+		histogram = append(histogram, fmt.Sprintf("/%d: %d", length, s.PrefixLengths[length]))
+	}
+	fmt.Printf("    prefix lengths: %s\n", strings.Join(histogram, ", "))
+
+	if len(s.LargestBlocks) > 0 {
+		blocks := make([]string, 0, len(s.LargestBlocks))
+		for _, block := range s.LargestBlocks {
+			blocks = append(blocks, block.String())
+		}
+		fmt.Printf("    largest blocks: %s\n", strings.Join(blocks, ", "))
+	}
+}